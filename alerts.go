@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+const CmdNotify = "notify"
+
+const (
+	// alertTick is how often we look for chats due for a check.
+	alertTick = time.Hour
+	// alertInterval is how often each chat's movies are checked.
+	alertInterval = 24 * time.Hour
+	// recentRelease is for how long after its release a movie is still announced as out.
+	recentRelease = 7 * 24 * time.Hour
+	dateFormat    = "2006-01-02"
+)
+
+// Alerts is who in a chat wants to hear about its movies coming out or reaching the services it
+// cares about, and what we knew of its movies when we last checked.
+type Alerts struct {
+	Subscribers []string
+	Services    []string
+	LastCheck   time.Time
+	Offered     map[string][]string
+}
+
+// alertCheck is what a check found out about a chat's movies, by IMDb ID, for the main loop to
+// announce.
+type alertCheck struct {
+	C        *Chat
+	Released map[string]string
+	Offered  map[string][]string
+}
+
+// upcoming returns whether e has not come out yet, as far as we know.
+func upcoming(e *Entry) bool {
+	if e.Released != "" {
+		return e.Released > time.Now().Format(dateFormat)
+	}
+	return e.Year >= time.Now().Year()
+}
+
+// scheduleChecks starts a check of every chat with subscribers that is due for one, covering all
+// of its lists, sending what it finds to done. It must be called from the main loop, and the
+// checks run on copies of the lists, so the main loop is free to change them meanwhile.
+func scheduleChecks(done chan<- *alertCheck) {
+	for _, C := range chatMap {
+		if len(C.alerts.Subscribers) == 0 || time.Since(C.alerts.LastCheck) < alertInterval {
+			continue
+		}
+		C.alerts.LastCheck = time.Now()
+		saveAlerts(C)
+		var E []Entry
+		for _, name := range listNames(C) {
+			if name == C.list {
+				E = append(E, C.movies...)
+			} else {
+				var L []Entry
+				loadList(listPath(C, name), &L)
+				E = append(E, L...)
+			}
+		}
+		country, S := C.country(), append([]string(nil), C.alerts.Services...)
+		log.Printf("Checking releases and offers of %d movies in %s", len(E), C.prefix)
+		go func(C *Chat) { done <- check(C, E, country, S) }(C)
+	}
+}
+
+// check refreshes the details of the upcoming movies of E, and looks up which of the services of
+// S offer each movie in country.
+func check(C *Chat, E []Entry, country string, S []string) *alertCheck {
+	released := make([]bool, len(E))
+	offered := make([][]string, len(E))
+	resolveAll(len(E), func(i int) *Entry {
+		e := &E[i]
+		if upcoming(e) && RefreshDetails(e) == nil {
+			released[i] = true
+		}
+		if len(S) > 0 {
+			for _, o := range offersIn(e, country) {
+				if o.on(S) && !inList(o.Name, offered[i]) {
+					offered[i] = append(offered[i], o.Name)
+				}
+			}
+		}
+		return e
+	})
+	a := &alertCheck{C, make(map[string]string), make(map[string][]string)}
+	for i := range E {
+		if released[i] {
+			a.Released[E[i].ID] = E[i].Released
+		}
+		if len(S) > 0 {
+			a.Offered[E[i].ID] = offered[i]
+		}
+	}
+	return a
+}
+
+// announce tells the chat of a check about the movies that came out or reached its services since
+// the last check, mentioning its subscribers.
+func announce(bot *tgbotapi.BotAPI, a *alertCheck) {
+	C := a.C
+	if C.alerts.Offered == nil {
+		C.alerts.Offered = make(map[string][]string)
+	}
+	recent := time.Now().Add(-recentRelease).Format(dateFormat)
+	var s string
+	done := make(map[string]bool)
+	for _, name := range listNames(C) {
+		L := C.movies
+		if name != C.list {
+			L = nil
+			loadList(listPath(C, name), &L)
+		}
+		for i := range L {
+			m := &L[i]
+			if r, e := a.Released[m.ID]; e {
+				m.Released = r
+				if !done[m.ID] && !upcoming(m) && r >= recent {
+					s += C.T("notify.released", m.Title, m.Year)
+				}
+			}
+			if O, e := a.Offered[m.ID]; e && !done[m.ID] {
+				prev, seen := C.alerts.Offered[m.ID]
+				var N []string
+				for _, o := range O {
+					if !inList(o, prev) {
+						N = append(N, o)
+					}
+				}
+				if seen && len(N) > 0 {
+					s += C.T("notify.offered", m.Title, m.Year, strings.Join(N, ", "))
+				}
+				C.alerts.Offered[m.ID] = O
+			}
+			done[m.ID] = true
+		}
+		if name != C.list {
+			saveList(listPath(C, name), L)
+		}
+	}
+	saveMovies(C)
+	saveAlerts(C)
+	if s == "" || len(C.alerts.Subscribers) == 0 {
+		return
+	}
+	s += "@" + strings.Join(C.alerts.Subscribers, " @")
+	bot.Send(tgbotapi.NewMessage(C.id, s))
+}
+
+func Notify(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	C := chat(u)
+	usr := u.Message.From.UserName
+	var s string
+	args := strings.Fields(u.Message.CommandArguments())
+	if len(args) > 0 && strings.ToLower(args[0]) == "services" {
+		C.alerts.Services = nil
+		for _, v := range args[1:] {
+			for _, sv := range strings.Split(v, ",") {
+				if sv = strings.TrimSpace(sv); sv != "" {
+					C.alerts.Services = append(C.alerts.Services, sv)
+				}
+			}
+		}
+		s = C.H("notify.services.none")
+		if len(C.alerts.Services) > 0 {
+			s = C.H("notify.services", escape(strings.Join(C.alerts.Services, ", ")))
+		}
+		if availability == nil {
+			s += C.H("notify.services.unknown")
+		}
+	} else if j := indexOf(usr, C.alerts.Subscribers); j >= 0 {
+		C.alerts.Subscribers = append(C.alerts.Subscribers[:j], C.alerts.Subscribers[j+1:]...)
+		s = C.H("notify.off")
+	} else {
+		C.alerts.Subscribers = append(C.alerts.Subscribers, usr)
+		s = C.H("notify.on")
+		if len(C.alerts.Services) > 0 {
+			s = C.H("notify.on.services", escape(strings.Join(C.alerts.Services, ", ")))
+		}
+	}
+	saveAlerts(C)
+	msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
+	msg.ReplyToMessageID = u.Message.MessageID
+	sendHTML(bot, msg)
+}
+
+func saveAlerts(C *Chat) {
+	f, err := os.Create(C.prefix + "alerts.json")
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	defer f.Close()
+	b, err := json.Marshal(C.alerts)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	_, err = f.Write(b)
+	if err != nil {
+		log.Printf("Error: %v", err)
+	}
+}
+
+func loadAlerts(C *Chat) {
+	f, err := os.Open(C.prefix + "alerts.json")
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	if len(b) < 5 {
+		return
+	}
+	err = json.Unmarshal(b, &C.alerts)
+	if err != nil {
+		log.Printf("Error: %v", err)
+	}
+}
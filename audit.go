@@ -0,0 +1,269 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const CmdLog = "log"
+
+const logPageSize = 10
+
+const (
+	ActAdd     = "add"
+	ActRemove  = "remove"
+	ActWatch   = "watch"
+	ActUnwatch = "unwatch"
+	ActArchive = "archive"
+	ActRestore = "restore"
+	ActRate    = "rate"
+	ActImport  = "import"
+	ActVote    = "vote"
+	ActUnvote  = "unvote"
+	ActEpisode = "episode"
+	ActMove    = "move"
+	ActTag     = "tag"
+	ActUntag   = "untag"
+	ActNote    = "note"
+	ActComment = "comment"
+	ActMerge   = "merge"
+)
+
+// Change is a single audit log record of a list mutation.
+type Change struct {
+	Time   time.Time
+	User   string
+	Action string
+	Before *Entry `json:",omitempty"`
+	After  *Entry `json:",omitempty"`
+}
+
+// snapshot returns a deep copy of e, so later changes to e do not leak into the log.
+func snapshot(e *Entry) *Entry {
+	if e == nil {
+		return nil
+	}
+	c := *e
+	c.WatchedBy = append([]string{}, e.WatchedBy...)
+	c.Votes = append([]string(nil), e.Votes...)
+	c.Tags = append([]string(nil), e.Tags...)
+	c.Notes = append([]Comment(nil), e.Notes...)
+	c.Comments = append([]Comment(nil), e.Comments...)
+	if e.Progress != nil {
+		c.Progress = make(map[string]Episode, len(e.Progress))
+		for k, v := range e.Progress {
+			c.Progress[k] = v
+		}
+	}
+	if e.Ratings != nil {
+		c.Ratings = make(map[string]int, len(e.Ratings))
+		for k, v := range e.Ratings {
+			c.Ratings[k] = v
+		}
+	}
+	return &c
+}
+
+// record appends a change done by the sender of u to the chat's audit log and to its file.
+func record(C *Chat, u *tgbotapi.Update, action string, before, after *Entry) {
+	c := Change{time.Now(), u.Message.From.UserName, action, snapshot(before), snapshot(after)}
+	C.changes = append(C.changes, c)
+	log.Printf("[%s] @%s %s", C.prefix, c.User, c.describe(C))
+	appendLog(C, &c)
+}
+
+// fillAddedBy sets who added each movie that predates Entry.AddedBy from the audit log.
+func fillAddedBy(C *Chat) {
+	A := make(map[string]string)
+	for _, c := range C.changes {
+		if (c.Action == ActAdd || c.Action == ActImport) && c.After != nil {
+			A[c.After.ID] = c.User
+		}
+	}
+	for _, L := range [][]Entry{C.movies, C.watchedMovies} {
+		for i := range L {
+			if L[i].AddedBy == "" {
+				L[i].AddedBy = A[L[i].ID]
+			}
+		}
+	}
+}
+
+// describe describes c in C's language.
+func (c *Change) describe(C *Chat) string {
+	e := c.After
+	if e == nil {
+		e = c.Before
+	}
+	if e == nil {
+		return c.Action
+	}
+	m := fmt.Sprintf("%s (%d)", e.Title, e.Year)
+	switch c.Action {
+	case ActAdd, ActRemove, ActNote, ActComment, ActMerge, ActArchive, ActRestore, ActImport, ActMove,
+		ActVote, ActUnvote:
+		return C.T("log."+c.Action, m)
+	case ActWatch, ActUnwatch:
+		var b []string
+		if c.Before != nil {
+			b = c.Before.WatchedBy
+		}
+		return C.T("log."+c.Action, m, strings.Join(b, ", "), strings.Join(e.WatchedBy, ", "))
+	case ActTag, ActUntag:
+		var b []string
+		if c.Before != nil {
+			b = c.Before.Tags
+		}
+		return C.T("log."+c.Action, m, strings.Join(b, ", "), strings.Join(e.Tags, ", "))
+	case ActEpisode:
+		return C.T("log.episode", e.Progress[c.User], m)
+	case ActRate:
+		return C.T("log.rate", m, e.Ratings[c.User])
+	}
+	return c.Action + " " + m
+}
+
+func Log(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	C := chat(u)
+	arg := strings.TrimSpace(u.Message.CommandArguments())
+	if arg == "export" {
+		exportLog(bot, u, C)
+		return
+	}
+	var s string
+	if len(C.changes) == 0 {
+		s = C.H("log.empty")
+		goto send
+	}
+	{
+		p := 1
+		if arg != "" {
+			var err error
+			p, err = strconv.Atoi(arg)
+			if err != nil || p < 1 {
+				s = C.H("log.usage")
+				goto send
+			}
+		}
+		pages := (len(C.changes) + logPageSize - 1) / logPageSize
+		if p > pages {
+			p = pages
+		}
+		s = C.H("log.header", p, pages)
+		hi := len(C.changes) - (p-1)*logPageSize
+		lo := hi - logPageSize
+		if lo < 0 {
+			lo = 0
+		}
+		for i := hi - 1; i >= lo; i-- {
+			c := &C.changes[i]
+			s += fmt.Sprintf("  %s @%s %s\n", c.Time.Format("2006-01-02 15:04"), escape(c.User),
+				escape(c.describe(C)))
+		}
+		if p < pages {
+			s += C.H("log.older", p+1)
+		}
+		s += C.H("log.footer")
+	}
+send:
+	msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
+	msg.ReplyToMessageID = u.Message.MessageID
+	sendHTML(bot, msg)
+}
+
+func exportLog(bot *tgbotapi.BotAPI, u *tgbotapi.Update, C *Chat) {
+	b, err := json.MarshalIndent(C.changes, "", "  ")
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	msg := tgbotapi.NewDocumentUpload(u.Message.Chat.ID, tgbotapi.FileBytes{Name: "log.json", Bytes: b})
+	msg.ReplyToMessageID = u.Message.MessageID
+	bot.Send(msg)
+}
+
+// appendLog appends c to the chat's audit log file, which holds one change per line, so recording
+// a change never rewrites the whole log.
+func appendLog(C *Chat, c *Change) {
+	f, err := os.OpenFile(C.prefix+"log.jsonl", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	defer f.Close()
+	b, err := json.Marshal(c)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	_, err = f.Write(append(b, '\n'))
+	if err != nil {
+		log.Printf("Error: %v", err)
+	}
+}
+
+// loadLog reads the chat's audit log, moving logs saved whole in log.json, as they used to be,
+// to log.jsonl.
+func loadLog(C *Chat) {
+	f, err := os.Open(C.prefix + "log.jsonl")
+	if os.IsNotExist(err) {
+		loadOldLog(C)
+		for i := range C.changes {
+			appendLog(C, &C.changes[i])
+		}
+		if len(C.changes) > 0 {
+			os.Remove(C.prefix + "log.json")
+		}
+		return
+	}
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	defer f.Close()
+	d := json.NewDecoder(f)
+	for {
+		var c Change
+		if err = d.Decode(&c); err == io.EOF {
+			break
+		} else if err != nil {
+			log.Printf("Error: %v", err)
+			break
+		}
+		C.changes = append(C.changes, c)
+	}
+}
+
+func loadOldLog(C *Chat) {
+	f, err := os.Open(C.prefix + "log.json")
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	defer f.Close()
+	s, err := f.Stat()
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	if s.Size() < 5 {
+		return
+	}
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	err = json.Unmarshal(b, &C.changes)
+	if err != nil {
+		log.Printf("Error: %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const CmdCountry = "country"
+
+const defaultCountry = "US"
+
+// Kinds of offers, as JustWatch calls them.
+const (
+	OfferStream = "flatrate"
+	OfferFree   = "free"
+	OfferAds    = "ads"
+	OfferRent   = "rent"
+	OfferBuy    = "buy"
+)
+
+// offerKinds are the kinds of offers in the order they are shown, with the key of the message
+// they are shown with.
+var offerKinds = []struct{ kind, key string }{
+	{OfferStream, "offer.stream"},
+	{OfferFree, "offer.free"},
+	{OfferAds, "offer.ads"},
+	{OfferRent, "offer.rent"},
+	{OfferBuy, "offer.buy"},
+}
+
+// Offer is a way of watching a title on some service.
+type Offer struct {
+	Service string `json:"package"`
+	Name    string `json:"provider"`
+	Kind    string `json:"monetization_type"`
+	URL     string `json:"url,omitempty"`
+}
+
+// Provider finds where titles can be watched in a country, given as an ISO 3166 code.
+type Provider interface {
+	Offers(e *Entry, country string) ([]Offer, error)
+}
+
+// JustWatch is a Provider for JustWatch-style JSON APIs, which answer GET
+// URL/titles/<IMDb ID>/offers?country=<country> with {"offers": [Offer...]}.
+type JustWatch struct {
+	URL string
+}
+
+func (p *JustWatch) Offers(e *Entry, country string) ([]Offer, error) {
+	b, err := cache.Fetch("offers/"+country+"/"+e.ID, offersTTL, func() ([]byte, error) {
+		return httpGet(fmt.Sprintf("%s/titles/%s/offers?country=%s", strings.TrimSuffix(p.URL, "/"),
+			url.PathEscape(e.ID), url.QueryEscape(country)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	var r struct {
+		Offers []Offer `json:"offers"`
+	}
+	err = json.Unmarshal(b, &r)
+	return r.Offers, err
+}
+
+// Fixture is a Provider that reads offers from a local JSON file mapping IMDb IDs to countries
+// to offers, for trying the bot out and testing it without an API.
+type Fixture struct {
+	offers map[string]map[string][]Offer
+}
+
+// newFixture reads the offers of a Fixture from the file at path, once.
+func newFixture(path string) (*Fixture, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var O map[string]map[string][]Offer
+	if err = json.Unmarshal(b, &O); err != nil {
+		return nil, err
+	}
+	p := &Fixture{make(map[string]map[string][]Offer, len(O))}
+	for id, M := range O {
+		p.offers[id] = make(map[string][]Offer, len(M))
+		for c, L := range M {
+			p.offers[id][strings.ToUpper(c)] = L
+		}
+	}
+	return p, nil
+}
+
+func (p *Fixture) Offers(e *Entry, country string) ([]Offer, error) {
+	return p.offers[e.ID][strings.ToUpper(country)], nil
+}
+
+// availability is where we look up offers, or nil if no provider is configured.
+var availability Provider
+
+// loadProvider configures availability from the given file, which holds either
+// {"Provider": "justwatch", "URL": "..."} or {"Provider": "fixture", "Path": "..."}.
+func loadProvider(filename string) {
+	f, err := os.Open(filename)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	var c struct{ Provider, URL, Path string }
+	if err = json.Unmarshal(b, &c); err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	switch c.Provider {
+	case "justwatch":
+		availability = &JustWatch{c.URL}
+	case "fixture":
+		p, err := newFixture(c.Path)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		availability = p
+	default:
+		log.Printf("Error: unknown availability provider %q", c.Provider)
+	}
+}
+
+// country returns the country whose offers C sees.
+func (C *Chat) country() string {
+	if C.settings.Country == "" {
+		return defaultCountry
+	}
+	return C.settings.Country
+}
+
+// offersFor returns the offers for e in C's country, or nil if there are none or we can't tell.
+func offersFor(C *Chat, e *Entry) []Offer {
+	return offersIn(e, C.country())
+}
+
+// offersIn returns the offers for e in country, or nil if there are none or we can't tell.
+func offersIn(e *Entry, country string) []Offer {
+	if availability == nil {
+		return nil
+	}
+	O, err := availability.Offers(e, country)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil
+	}
+	return O
+}
+
+// where describes the offers of O in C's language, one line per kind.
+func where(C *Chat, O []Offer) string {
+	var s string
+	for _, k := range offerKinds {
+		var N []string
+		for _, o := range O {
+			if o.Kind == k.kind && !inList(o.Name, N) {
+				N = append(N, o.Name)
+			}
+		}
+		if len(N) > 0 {
+			s += fmt.Sprintf("\n%s: %s", C.T(k.key), strings.Join(N, ", "))
+		}
+	}
+	return s
+}
+
+// onService parses the value of an on:service filter, which keeps movies offered on any of the
+// comma-separated services in C's country.
+func onService(C *Chat, v string) (cond, error) {
+	if availability == nil {
+		return nil, filterErrorf("filter.on")
+	}
+	S := strings.Split(v, ",")
+	resolveAll(len(C.movies), func(i int) *Entry {
+		offersFor(C, &C.movies[i])
+		return nil
+	})
+	return func(e *Entry) bool {
+		for _, o := range offersFor(C, e) {
+			if o.on(S) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// on returns whether o is an offer of any of the services of S, given by their ID or name.
+func (o *Offer) on(S []string) bool {
+	for _, s := range S {
+		if strings.EqualFold(s, o.Service) || matchScore(s, o.Name) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func Country(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	C := chat(u)
+	var s string
+	if c := strings.ToUpper(strings.TrimSpace(u.Message.CommandArguments())); c == "" {
+		s = C.H("country.current", escape(C.country()))
+	} else if len(c) != 2 || strings.Trim(c, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		s = C.H("country.unknown", escape(c))
+	} else {
+		C.settings.Country = c
+		saveSettings(C)
+		s = C.H("country.set", c)
+	}
+	msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
+	msg.ReplyToMessageID = u.Message.MessageID
+	sendHTML(bot, msg)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func fixture(t *testing.T) *Fixture {
+	p, err := newFixture("testdata/offers.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestFixtureOffers(t *testing.T) {
+	p := fixture(t)
+	tests := []struct {
+		id, country string
+		want        []string
+	}{
+		{"tt0111161", "US", []string{"Netflix", "Apple TV"}},
+		{"tt0111161", "us", []string{"Netflix", "Apple TV"}},
+		{"tt0111161", "BR", []string{"Max"}},
+		{"tt0111161", "PT", nil},
+		{"tt0068646", "US", []string{"Amazon Prime Video"}},
+		{"tt0000001", "US", nil},
+	}
+	for _, test := range tests {
+		O, err := p.Offers(&Entry{ID: test.id}, test.country)
+		if err != nil {
+			t.Fatalf("Offers(%s, %s): %v", test.id, test.country, err)
+		}
+		var got []string
+		for _, o := range O {
+			got = append(got, o.Name)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Offers(%s, %s) = %v, want %v", test.id, test.country, got, test.want)
+		}
+	}
+}
+
+func TestOnFilter(t *testing.T) {
+	defer func(p Provider) { availability = p }(availability)
+	availability = fixture(t)
+	C := &Chat{movies: []Entry{
+		{Title: "The Shawshank Redemption", ID: "tt0111161"},
+		{Title: "The Godfather", ID: "tt0068646"},
+		{Title: "Unknown", ID: "tt0000001"},
+	}}
+	tests := []struct {
+		args, country string
+		want          []int
+	}{
+		{"on:netflix", "", []int{0}},
+		{"on:nfx", "", []int{0}},
+		{"on:prime", "", []int{1}},
+		{"on:netflix,prime", "", []int{0, 1}},
+		{"on:max", "", nil},
+		{"on:max", "BR", []int{0}},
+		{"on:netflix", "BR", nil},
+	}
+	for _, test := range tests {
+		C.settings.Country = test.country
+		F, rest, err := parseFilter(C, test.args)
+		if err != nil || rest != "" {
+			t.Fatalf("parseFilter(%q) = %q, %v", test.args, rest, err)
+		}
+		var got []int
+		for i := range C.movies {
+			if F.Match(&C.movies[i]) {
+				got = append(got, i)
+			}
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s in %q matched %v, want %v", test.args, test.country, got, test.want)
+		}
+	}
+	availability = nil
+	if _, _, err := parseFilter(C, "on:netflix"); err == nil {
+		t.Errorf("on: without a provider should fail")
+	}
+}
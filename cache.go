@@ -0,0 +1,214 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	cacheDir     = "cache/"
+	maxCacheSize = 256 << 20
+
+	searchTTL   = 7 * 24 * time.Hour
+	entryTTL    = 30 * 24 * time.Hour
+	ratingTTL   = 24 * time.Hour
+	offersTTL   = 24 * time.Hour
+	posterTTL   = 0
+	httpTimeout = 15 * time.Second
+)
+
+// client is the HTTP client for every request to IMDb, so that a slow IMDb does not hang the bot.
+var client = &http.Client{Timeout: httpTimeout}
+
+// cacheItem is the index record of a cached value. Zero Expires means it never expires.
+type cacheItem struct {
+	Key     string
+	Size    int64
+	Used    time.Time
+	Expires time.Time
+}
+
+// Cache is a persistent on-disk key-value cache shared by every chat. Once it holds more than
+// max bytes, least recently used values are evicted.
+type Cache struct {
+	mu    sync.Mutex
+	dir   string
+	max   int64
+	size  int64
+	items map[string]*cacheItem
+}
+
+var cache = newCache(cacheDir, maxCacheSize)
+
+func newCache(dir string, max int64) *Cache {
+	c := &Cache{dir: dir, max: max, items: make(map[string]*cacheItem)}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		log.Printf("Error: %v", err)
+	}
+	b, err := ioutil.ReadFile(dir + "index.json")
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return c
+	}
+	if err = json.Unmarshal(b, &c.items); err != nil {
+		log.Printf("Error: %v", err)
+	}
+	for _, it := range c.items {
+		c.size += it.Size
+	}
+	return c
+}
+
+func (c *Cache) filename(key string) string {
+	h := sha1.Sum([]byte(key))
+	return c.dir + hex.EncodeToString(h[:])
+}
+
+func (c *Cache) get(key string, stale bool) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, e := c.items[key]
+	if !e || (!stale && !it.Expires.IsZero() && time.Now().After(it.Expires)) {
+		return nil, false
+	}
+	b, err := ioutil.ReadFile(c.filename(key))
+	if err != nil {
+		log.Printf("Error: %v", err)
+		c.size -= it.Size
+		delete(c.items, key)
+		return nil, false
+	}
+	it.Used = time.Now()
+	return b, true
+}
+
+// Get returns the value of key if it is cached and has not expired.
+func (c *Cache) Get(key string) ([]byte, bool) {
+	return c.get(key, false)
+}
+
+// Stale returns the value of key if it is cached, even if it has expired.
+func (c *Cache) Stale(key string) ([]byte, bool) {
+	return c.get(key, true)
+}
+
+// Put caches b as the value of key for ttl, or forever if ttl is zero.
+func (c *Cache) Put(key string, b []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := ioutil.WriteFile(c.filename(key), b, 0644); err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	if it, e := c.items[key]; e {
+		c.size -= it.Size
+	}
+	it := &cacheItem{Key: key, Size: int64(len(b)), Used: time.Now()}
+	if ttl > 0 {
+		it.Expires = it.Used.Add(ttl)
+	}
+	c.items[key] = it
+	c.size += it.Size
+	c.evict()
+	c.saveIndex()
+}
+
+// Expire makes the value of key expire now, so the next Fetch looks it up again, but still falls
+// back on it if that fails.
+func (c *Cache) Expire(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, e := c.items[key]
+	if !e {
+		return
+	}
+	it.Expires = time.Now()
+	c.saveIndex()
+}
+
+// Fetch returns the value of key, calling fetch and caching its result for ttl on a miss. If
+// fetch fails, an expired value is returned instead, if there is one.
+func (c *Cache) Fetch(key string, ttl time.Duration, fetch func() ([]byte, error)) ([]byte, error) {
+	if b, ok := c.Get(key); ok {
+		return b, nil
+	}
+	b, err := fetch()
+	if err != nil {
+		log.Printf("Error: %v", err)
+		if s, ok := c.Stale(key); ok {
+			log.Printf("Using stale cache for %s", key)
+			return s, nil
+		}
+		return nil, err
+	}
+	c.Put(key, b, ttl)
+	return b, nil
+}
+
+// evict removes least recently used values until the cache fits in its size limit.
+func (c *Cache) evict() {
+	if c.size <= c.max {
+		return
+	}
+	I := make([]*cacheItem, 0, len(c.items))
+	for _, it := range c.items {
+		I = append(I, it)
+	}
+	sort.Slice(I, func(i, j int) bool { return I[i].Used.Before(I[j].Used) })
+	for _, it := range I {
+		if c.size <= c.max {
+			break
+		}
+		if err := os.Remove(c.filename(it.Key)); err != nil {
+			log.Printf("Error: %v", err)
+		}
+		c.size -= it.Size
+		delete(c.items, it.Key)
+	}
+}
+
+func (c *Cache) saveIndex() {
+	b, err := json.Marshal(c.items)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	if err = ioutil.WriteFile(c.dir+"index.json", b, 0644); err != nil {
+		log.Printf("Error: %v", err)
+	}
+}
+
+// httpGet returns the body of url, failing on non-2xx responses.
+func httpGet(url string) ([]byte, error) {
+	return httpGetLang(url, "")
+}
+
+// httpGetLang is httpGet asking for the body in the languages of the Accept-Language header
+// accept, if any.
+func httpGetLang(url, accept string) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept-Language", accept)
+	}
+	r, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode < 200 || r.StatusCode > 299 {
+		return nil, fmt.Errorf("GET %s: %s", url, r.Status)
+	}
+	return ioutil.ReadAll(r.Body)
+}
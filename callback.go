@@ -0,0 +1,41 @@
+package main
+
+import (
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"log"
+	"strings"
+)
+
+// keepButtons are the commands whose buttons can be pressed by many people, and so are not
+// removed once pressed.
+var keepButtons = map[string]bool{
+	CmdTonight: true,
+	CmdAddTo:   true,
+	CmdTag:     true,
+	CmdUntag:   true,
+}
+
+// Callback handles a button press. Buttons carry a command line (without the leading slash) as
+// data, which is run as if whoever pressed the button had sent it in reply to the button's
+// message. The buttons are then removed so the choice is not made twice, unless they are meant
+// for everyone.
+func Callback(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	q := u.CallbackQuery
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(q.ID, ""))
+	F := strings.Fields(q.Data)
+	if q.Message == nil || len(F) == 0 {
+		return
+	}
+	log.Printf("Button %q pressed by %s", q.Data, q.From.UserName)
+	cmd := F[0]
+	if !keepButtons[cmd] {
+		edit := tgbotapi.NewEditMessageReplyMarkup(q.Message.Chat.ID, q.Message.MessageID,
+			tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}})
+		bot.Send(edit)
+	}
+	m := *q.Message
+	m.From = q.From
+	m.Text = "/" + q.Data
+	m.Entities = &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(cmd) + 1}}
+	loop(bot, &tgbotapi.Update{UpdateID: u.UpdateID, Message: &m})
+}
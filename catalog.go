@@ -0,0 +1,550 @@
+package main
+
+// catalog holds each message in each language. Messages with plural forms have a key for each
+// form, ending in .one or .other.
+var catalog = map[string]map[string]string{
+	LangEnglish: {
+		"help.list": "List of commands:\n" +
+			"  `/all`: prints current movie list\n" +
+			"  `/all grid`: sends current movie list as a picture of their covers\n" +
+			"  `/all key:value ...`: prints only movies matching every filter, where `key` is one of " +
+			"`genre`, `director`, `actor`, `lang`, `cert`, `decade` (e.g. `decade:1980s`), `runtime` (max, e.g. `runtime:2h`), " +
+			"`rating` (min IMDb rating), `unwatched-by` (e.g. `unwatched-by:@ana,@bob`), `added-by`, `tag` or `on` (e.g. `on:netflix`)\n" +
+			"  `/show i`: prints more info on the `i`-th item of list\n" +
+			"  `/remove i`: removes `i`-th item from list\n" +
+			"  `/show title`, `/remove title`, `/watch title`, `/unwatch title`: same, but finds the movie by (part of) its title\n" +
+			"  `/add title`: adds top search result of `title` to list\n" +
+			"  `/add title1; title2; ...`: adds many titles at once (one per line works too)\n" +
+			"  `/query title`: queries IMDb for `title`\n" +
+			"  `/watch i1 i2 ...`: mark all `ij` instances as `watched` by you\n" +
+			"  `/watch i s2e3`: marks up to episode 3 of season 2 of the `i`-th series as watched by you\n" +
+			"  `/unwatch i1 i2 ...`: mark all `ij` instances as `unwatched` by you\n" +
+			"  `/restore`: restore last automatically removed items of movie list\n" +
+			"  `/watched`: prints list of watched movies\n" +
+			"  `/watched username`: prints list of movies watched by username\n" +
+			"  `/rate i score`: rates the `i`-th movie from 1 to 10 (`/rate watched i score` for the watched list)\n" +
+			"  `/export format key:value ...`: sends our lists as a `csv`, `json` or `letterboxd` file, keeping only movies matching the filters\n" +
+			"  `/import`: as a file's caption, adds every movie in an IMDb or Letterboxd CSV or a list of titles\n" +
+			"  `/import undo`: removes the movies added by the last import",
+		"help.draw": "Choosing and organising:\n" +
+			"  `/draw n=1`: draws n movies at random (default n=1)\n" +
+			"  `/draw n grid`: same, but sends the covers of the drawn movies in one picture\n" +
+			"  `/draw time=3h`: draws movies that can all be watched in 3 hours\n" +
+			"  `/draw n key:value ...`: draws only movies matching the same filters as `/all`\n" +
+			"  `/draw n again`: also draws movies drawn in the last week, which are left out otherwise\n" +
+			"  `/refresh i`: fetches the details of the `i`-th movie again (`/refresh` for all movies)\n" +
+			"  `/vote i1 i2 ...`: votes for (or takes back your vote for) each `ij`-th movie\n" +
+			"  `/top`: shows the most voted movies\n" +
+			"  `/tonight @a @b ...`: tells me who's watching tonight, so `/all`, `/draw` and `/top` only show " +
+			"what the fewest of you have seen (`/tonight` asks everyone, `/tonight clear` forgets)\n" +
+			"  `/tag i #tag1 #tag2 ...`: tags the `i`-th movie, or suggests tags if you give none\n" +
+			"  `/untag i #tag1 ...`: removes tags from the `i`-th movie\n" +
+			"  `/note i text`: leaves a note on the `i`-th movie (who recommended it, where to stream it...); `/note i` shows them\n" +
+			"  replying to a movie's `/show` card: adds your reply to its discussion\n" +
+			"  `/country code`: sets the country (e.g. `BR`) whose streaming services `/show` and `on:` look at\n" +
+			"  `/notify`: tells you when movies in our list come out or reach our services (send again to stop)\n" +
+			"  `/notify services netflix,max`: sets the services `/notify` looks out for\n" +
+			"  `/dedupe`: finds movies added twice and offers to merge who watched, voted and noted them",
+		"help.more": "Lists, settings and more:\n" +
+			"  `/list`: shows this chat's lists; `/list create|delete|switch name` and `/list rename old new` manage them\n" +
+			"  `/list move name i1 i2 ...`: moves movies from the list in use to another\n" +
+			"  `list:name` after any command: makes it use another list, e.g. `/all list:kids`\n" +
+			"  `/recommend`: suggests movies like the ones you've watched and liked\n" +
+			"  `/language en|pt`: changes the language I speak\n" +
+			"  `/save`: force save everything\n" +
+			"  `/ranking`: shows top movie-watchers\n" +
+			"  `/log page=1`: shows who changed the list and when\n" +
+			"  `/log export`: sends the whole change log as a file\n" +
+			"In private, your list is your own, and:\n" +
+			"  `/groups`: lists the groups you're in\n" +
+			"  `/mine`: lists what you haven't seen in your list and every group's\n" +
+			"  `/addto i title`: adds a movie to the `i`-th group's list without a word to the group\n" +
+			"  `@bot title` (in any chat): shares a movie from IMDb or your lists, with a button to add it to a list\n" +
+			"**Important:** before `/add`-ing, `/query` first to make sure it's the right movie!",
+		"all.empty":           "Movie list is empty! Start adding movies with /add!",
+		"all.header":          "To-watch movie list:\n",
+		"all.header.list":     "To-watch movie list (%s):\n",
+		"all.header.guests":   "To-watch movie list for tonight (@%s):\n",
+		"all.votes.one":       " [%d vote]",
+		"all.votes.other":     " [%d votes]",
+		"all.footer":          "`/show i` - shows more information on the `i`-th movie.",
+		"all.grid":            "To-watch movie list",
+		"watched.unknown":     "I don't know who %s is!",
+		"watched.pending":     "Movies watched by %s still in the to-watch list:\n",
+		"watched.archived":    "Movies watched by %s in the watched list:\n",
+		"watched.total.one":   "Total: %d movie watched",
+		"watched.total.other": "Total: %d movies watched",
+		"watched.empty":       "You have not watched any movies yet! :(",
+		"watched.header":      "Watched movie list:\n",
+		"draw.positive":       "I can only draw a positive number of movies!",
+		"draw.none":           "I couldn't find any movie that fits! :(",
+		"draw.chosen.one":     "I've chosen this movie for you to watch. Have fun! :)",
+		"draw.chosen.other":   "I've chosen these movies for you to watch. Have fun! :)",
+		"draw.budget":         "That's %s out of your %s.\n",
+		"draw.footer": "You can find out more about each movie with `/show i` where `i` is the number in " +
+			"{curly braces}. Don't forget to `/watch i` when you're finished watching movie `i`!",
+		"archived.one": "I've removed the following movie because everyone has watched it!\n%s" +
+			"To undo this change, tell me to `/restore`.",
+		"archived.other": "I've removed the following movies because everyone has watched them!\n%s" +
+			"To undo these changes, tell me to `/restore`.",
+		"language.current":   "I speak %s here. Change it with `/language code`, where `code` is one of %s.",
+		"language.unknown":   "I don't speak %s! Try one of %s.",
+		"language.set":       "From now on I'll speak English here.",
+		"add.present":        "Movie is already in our to-watch list!",
+		"add.suggest":        " (did you mean %s?)",
+		"add.added":          "Added to our to-watch list:\n",
+		"add.already":        "Already in our to-watch list:\n",
+		"add.missing":        "Could not find:\n",
+		"add.none":           "Could not find requested query!",
+		"show.runtime":       " - %d min",
+		"show.released":      " - out %s",
+		"show.seasons.one":   ", %d season",
+		"show.seasons.other": ", %d seasons",
+		"show.directors":     "\nDirected by %s",
+		"show.cast":          "\nStarring %s",
+		"show.language":      "\nLanguage: %s",
+		"show.tags":          "\nTags: %s",
+		"show.rating":        "\nRating: %.1f/10.0",
+		"show.watched":       "\nWatched by (%d):",
+		"show.progress":      "\nProgress:",
+		"show.ratings":       "\nOur ratings:",
+		"show.notes":         "Notes",
+		"show.discussion":    "Discussion",
+		"remove.done":        "Removing %s (%d) from movie list...",
+		"watch.film":         "%s (%d) is not a series!\n",
+		"watch.episode":      "%s (%d) has no %s!\n",
+		"watch.next":         "Next up for you in %s (%d): %s.\n",
+		"watch.finished":     "You finished %s (%d)!\n",
+		"rate.done":          "You rated %s (%d) %d/10.",
+		"rate.usage":         "Usage: `/rate i score` or `/rate watched i score`, where `score` goes from 1 to 10.",
+		"refresh.done.one":   "Updated the details of %d out of %d movie.",
+		"refresh.done.other": "Updated the details of %d out of %d movies.",
+		"ranking.header":     "Ranking of number of watched movies:\n",
+		"pick.none":          "No movie in our list looks like %s!",
+		"pick.which":         "Which one did you mean?",
+		"note.usage":         "Which movie? Write /note i text, with i from /all.",
+		"note.none":          "No notes on %s (%d) yet!",
+		"note.header":        "Notes on %s (%d):",
+		"note.done":          "Noted on %s (%d).",
+		"tag.done":           "Tagged %s (%d) with %s.",
+		"tag.present":        "%s (%d) already has those tags.",
+		"tag.usage":          "Tag %s (%d) with /tag %d #tag.",
+		"tag.which":          "Which tag did you mean?",
+		"untag.none":         "%s (%d) has no tags!",
+		"untag.which":        "Which tag should I remove?",
+		"untag.missing":      "%s (%d) has none of those tags!",
+		"untag.done":         "Removed %s from %s (%d).",
+		"dedupe.changed":     "The list changed since I asked! Try `/dedupe` again.",
+		"dedupe.merged":      "Merged %s (%d) {%d} into %s (%d) {%d}.",
+		"dedupe.same":        "They are the same movie on IMDb, so I can only merge them.",
+		"dedupe.kept":        "Got it, %s (%d) and %s (%d) are different movies.",
+		"dedupe.none":        "No duplicates in our list!",
+		"dedupe.ask": "%s (%d) {%d} and %s (%d) {%d} look like the same movie. " +
+			"Merge the second into the first?",
+		"dedupe.merge": "Merge",
+		"dedupe.keep":  "Keep both",
+		"offer.stream": "Stream",
+		"offer.free":   "Free",
+		"offer.ads":    "Free with ads",
+		"offer.rent":   "Rent",
+		"offer.buy":    "Buy",
+		"country.current": "I look for where to watch movies in %s. Change it with `/country code`, e.g. " +
+			"`/country BR`.",
+		"country.unknown":         "%s is not a country code! Try a two-letter one, like `BR` or `US`.",
+		"country.set":             "I'll look for where to watch movies in %s.",
+		"notify.released":         "%s (%d) is out!\n",
+		"notify.offered":          "%s (%d) is now on %s!\n",
+		"notify.services.none":    "I won't look out for streaming services anymore.",
+		"notify.services":         "I'll tell whoever wants to know when our movies reach %s.",
+		"notify.services.unknown": " Though I don't know where movies are streaming yet!",
+		"notify.off":              "You won't hear from me about releases anymore.",
+		"notify.on":               "I'll let you know when movies in our list come out. Send `/notify` again to stop.",
+		"notify.on.services": "I'll let you know when movies in our list come out or reach %s. Send " +
+			"`/notify` again to stop.",
+		"private.unknown":   "I can't tell who you are!",
+		"groups.private":    "Talk to me in private to see your groups!",
+		"groups.none":       "I haven't seen you in any group yet! Say something in a group I'm in first.",
+		"groups.header":     "Your groups:\n",
+		"groups.item.one":   "  %d. %s - %d movie, %d you haven't seen\n",
+		"groups.item.other": "  %d. %s - %d movies, %d you haven't seen\n",
+		"groups.footer": "`/mine` lists what you haven't seen in all of them, and `/addto i title` adds a " +
+			"movie to the `i`-th group without bothering them.",
+		"mine.private":  "Talk to me in private to see your movies from every group!",
+		"mine.own":      "Your own list:\n",
+		"mine.none":     "You've seen everything on your lists!",
+		"addto.private": "Talk to me in private to add movies to your groups quietly!",
+		"addto.usage": "Tell me which group and which movie, e.g. `/addto 0 title`. See your groups with " +
+			"`/groups`.",
+		"addto.group":            "You're not in that group! See your groups with `/groups`.",
+		"addto.present":          "%s (%d) is already in %s's list!",
+		"addto.done":             "Added %s (%d) to %s's list.",
+		"addto.button":           "Add to %s",
+		"addto.offer":            "Add it to a group's list too?",
+		"list.unknown":           "There's no list called %s! See your lists with `/list`.",
+		"list.present":           "%s (%d) is already in %s!\n",
+		"list.moved":             "Moved %s (%d) to %s.\n",
+		"list.header":            "Your lists:\n",
+		"list.item.one":          "  %s - %d movie",
+		"list.item.other":        "  %s - %d movies",
+		"list.current":           " (in use)",
+		"list.footer":            "`/list switch name` changes lists, and `list:name` makes any command use another list.",
+		"list.name":              "List names can only have letters, digits, `-` and `_`!",
+		"list.exists":            "There's already a list called %s!",
+		"list.created":           "Created list %[1]s. Use it with `/list switch %[1]s`.",
+		"list.rename.refused":    "I can't rename %s!",
+		"list.rename.failed":     "I couldn't rename %s!",
+		"list.renamed":           "Renamed %s to %s.",
+		"list.delete.refused":    "I can't delete %s!",
+		"list.delete.full.one":   "%s still has %d movie! Move or remove it first.",
+		"list.delete.full.other": "%s still has %d movies! Move or remove them first.",
+		"list.deleted":           "Deleted list %s.",
+		"list.missing":           "There's no list called %s!",
+		"list.switched.one":      "Now using %s (%d movie).",
+		"list.switched.other":    "Now using %s (%d movies).",
+		"list.move.refused":      "I can't move movies to %s!",
+		"list.usage": "Try `/list`, `/list create name`, `/list rename old new`, `/list delete name`, " +
+			"`/list switch name` or `/list move name i1 i2 ...`.",
+		"tonight.ask":   "Who's watching tonight?",
+		"tonight.sofar": " So far: @%s.",
+		"tonight.in":    "I'm in!",
+		"tonight.out":   "I'm out",
+		"tonight.none":  "Nobody is watching tonight. `/all`, `/draw` and `/top` are back to normal.",
+		"tonight.set": "Watching tonight: @%s. `/all`, `/draw` and `/top` only show what the fewest of " +
+			"you have seen.",
+		"vote.removed":       "You took back your vote for %s (%d).\n",
+		"vote.added":         "You voted for %s (%d).\n",
+		"top.header":         "Most voted movies:\n",
+		"top.header.guests":  "Most voted movies for tonight (@%s):\n",
+		"top.item.one":       "  %d. %s (%d) {%d} - %d vote\n",
+		"top.item.other":     "  %d. %s (%d) {%d} - %d votes\n",
+		"top.none":           "Nobody has voted yet! Vote for movies with `/vote i`.",
+		"recommend.none":     "I don't know enough about what you like yet! Watch and `/rate` some movies first.",
+		"recommend.pending":  "I'm still reading up on movies you might like. Ask me again in a minute!",
+		"recommend.header":   "You might like:\n",
+		"recommend.because":  "\n     because you liked %s (%d)\n",
+		"recommend.footer":   "Add one with `/add title`.",
+		"log.add":            "added %s",
+		"log.remove":         "removed %s",
+		"log.watch":          "watched %s [%s -> %s]",
+		"log.unwatch":        "unwatched %s [%s -> %s]",
+		"log.tag":            "tagged %s [%s -> %s]",
+		"log.untag":          "untagged %s [%s -> %s]",
+		"log.note":           "left a note on %s",
+		"log.comment":        "commented on %s",
+		"log.merge":          "merged a duplicate into %s",
+		"log.archive":        "archived %s (watched by everyone)",
+		"log.restore":        "restored %s",
+		"log.import":         "imported %s",
+		"log.episode":        "watched %s of %s",
+		"log.move":           "moved %s to another list",
+		"log.vote":           "voted for %s",
+		"log.unvote":         "took back their vote for %s",
+		"log.rate":           "rated %s %d/10",
+		"log.empty":          "Nothing has happened to the list yet!",
+		"log.usage":          "Usage: `/log page` where `page` is a positive number.",
+		"log.header":         "List changes (page %d/%d):\n",
+		"log.older":          "Older changes: `/log %d`. ",
+		"log.footer":         "Full log: `/log export`.",
+		"show.progress.next": "\n  @%s: watched %s, next %s",
+		"show.progress.done": "\n  @%s: finished",
+		"import.usage": "Send me an IMDb or Letterboxd CSV export, or a text file with one title per " +
+			"line, with `/import` as its caption (or reply to the file with `/import`). `/import undo` " +
+			"removes everything the last import added.",
+		"import.big":           "That file is too big for me!",
+		"import.download":      "I couldn't download that file!",
+		"import.empty":         "I couldn't find any movies in that file!",
+		"import.done.one":      "Imported %d movie, %d were already in our to-watch list.\n",
+		"import.done.other":    "Imported %d movies, %d were already in our to-watch list.\n",
+		"import.missing.one":   "I couldn't find this %d:\n",
+		"import.missing.other": "I couldn't find these %d:\n",
+		"import.row":           "  line %d: %s\n",
+		"import.more":          "  …and %d more\n",
+		"import.undo":          "Changed your mind? `/import undo`",
+		"import.undo.none":     "There's no import to undo!",
+		"import.undone.one":    "Removed %d imported movie from the to-watch list.",
+		"import.undone.other":  "Removed %d imported movies from the to-watch list.",
+		"filter.rating":        "%s is not a rating",
+		"filter.decade":        "%s is not a decade",
+		"filter.duration":      "%s is not a duration",
+		"filter.key":           "I don't know how to filter by %s",
+		"filter.on":            "I don't know where movies are streaming",
+		"export.usage":         "Usage: `/export csv`, `/export json` or `/export letterboxd username`.",
+		"inline.add":           "Add to a list",
+		"inline.list":          "In your to-watch list",
+		"inline.imdb":          "From IMDb",
+		"start.none":           "I couldn't find that movie on IMDb!",
+		"grid.more":            "\n(only the first %d fit in the picture)",
+	},
+	LangPortuguese: {
+		"help.list": "Lista de comandos:\n" +
+			"  `/all`: mostra a lista de filmes atual\n" +
+			"  `/all grid`: envia a lista de filmes atual como uma imagem com os pôsteres\n" +
+			"  `/all chave:valor ...`: mostra só os filmes que passam em todos os filtros, onde `chave` é " +
+			"`genre`, `director`, `actor`, `lang`, `cert`, `decade` (ex. `decade:1980s`), `runtime` (máxima, ex. `runtime:2h`), " +
+			"`rating` (nota mínima no IMDb), `unwatched-by` (ex. `unwatched-by:@ana,@bob`), `added-by`, `tag` ou `on` (ex. `on:netflix`)\n" +
+			"  `/show i`: mostra mais sobre o `i`-ésimo item da lista\n" +
+			"  `/remove i`: tira o `i`-ésimo item da lista\n" +
+			"  `/show título`, `/remove título`, `/watch título`, `/unwatch título`: o mesmo, mas acha o filme por (parte do) título\n" +
+			"  `/add título`: adiciona à lista o primeiro resultado da busca por `título`\n" +
+			"  `/add título1; título2; ...`: adiciona vários títulos de uma vez (um por linha também funciona)\n" +
+			"  `/query título`: busca `título` no IMDb\n" +
+			"  `/watch i1 i2 ...`: marca cada `ij` como assistido por você\n" +
+			"  `/watch i s2e3`: marca até o episódio 3 da temporada 2 da `i`-ésima série como assistido por você\n" +
+			"  `/unwatch i1 i2 ...`: marca cada `ij` como não assistido por você\n" +
+			"  `/restore`: devolve à lista os últimos filmes tirados automaticamente\n" +
+			"  `/watched`: mostra a lista de filmes assistidos\n" +
+			"  `/watched usuário`: mostra os filmes assistidos por usuário\n" +
+			"  `/rate i nota`: dá uma nota de 1 a 10 ao `i`-ésimo filme (`/rate watched i nota` para a lista de assistidos)\n" +
+			"  `/export formato chave:valor ...`: envia nossas listas num arquivo `csv`, `json` ou `letterboxd`, só com os filmes que passam nos filtros\n" +
+			"  `/import`: como legenda de um arquivo, adiciona cada filme de um CSV do IMDb ou Letterboxd ou de uma lista de títulos\n" +
+			"  `/import undo`: tira os filmes adicionados pela última importação",
+		"help.draw": "Escolhendo e organizando:\n" +
+			"  `/draw n=1`: sorteia n filmes (n=1 se não disser)\n" +
+			"  `/draw n grid`: o mesmo, mas envia os pôsteres dos filmes sorteados numa imagem\n" +
+			"  `/draw time=3h`: sorteia filmes que dá para ver todos em 3 horas\n" +
+			"  `/draw n chave:valor ...`: sorteia só filmes que passam nos mesmos filtros do `/all`\n" +
+			"  `/draw n again`: sorteia também filmes sorteados na última semana, que ficam de fora se não\n" +
+			"  `/refresh i`: busca de novo os detalhes do `i`-ésimo filme (`/refresh` para todos)\n" +
+			"  `/vote i1 i2 ...`: vota (ou tira seu voto) em cada `ij`-ésimo filme\n" +
+			"  `/top`: mostra os filmes mais votados\n" +
+			"  `/tonight @a @b ...`: me diz quem vai assistir hoje, para `/all`, `/draw` e `/top` só mostrarem " +
+			"o que menos de vocês viram (`/tonight` pergunta a todos, `/tonight clear` esquece)\n" +
+			"  `/tag i #tag1 #tag2 ...`: marca o `i`-ésimo filme com tags, ou sugere tags se você não der nenhuma\n" +
+			"  `/untag i #tag1 ...`: tira tags do `i`-ésimo filme\n" +
+			"  `/note i texto`: deixa uma nota no `i`-ésimo filme (quem recomendou, onde assistir...); `/note i` mostra as notas\n" +
+			"  responder ao card de `/show` de um filme: adiciona sua resposta à conversa sobre ele\n" +
+			"  `/country código`: define o país (ex. `BR`) cujos serviços de streaming `/show` e `on:` consultam\n" +
+			"  `/notify`: avisa você quando filmes da lista estreiam ou chegam aos nossos serviços (envie de novo para parar)\n" +
+			"  `/notify services netflix,max`: define os serviços que o `/notify` acompanha\n" +
+			"  `/dedupe`: acha filmes adicionados duas vezes e oferece juntar quem assistiu, votou e anotou",
+		"help.more": "Listas, configurações e mais:\n" +
+			"  `/list`: mostra as listas deste chat; `/list create|delete|switch nome` e `/list rename antigo novo` cuidam delas\n" +
+			"  `/list move nome i1 i2 ...`: move filmes da lista em uso para outra\n" +
+			"  `list:nome` depois de qualquer comando: faz ele usar outra lista, ex. `/all list:kids`\n" +
+			"  `/recommend`: sugere filmes parecidos com os que vocês viram e gostaram\n" +
+			"  `/language en|pt`: muda a língua em que eu falo\n" +
+			"  `/save`: força salvar tudo\n" +
+			"  `/ranking`: mostra quem mais assiste filmes\n" +
+			"  `/log page=1`: mostra quem mudou a lista e quando\n" +
+			"  `/log export`: envia o histórico inteiro de mudanças num arquivo\n" +
+			"No privado, sua lista é só sua, e:\n" +
+			"  `/groups`: lista os grupos em que você está\n" +
+			"  `/mine`: lista o que você não viu na sua lista e na de cada grupo\n" +
+			"  `/addto i título`: adiciona um filme à lista do `i`-ésimo grupo sem dizer nada no grupo\n" +
+			"  `@bot título` (em qualquer chat): compartilha um filme do IMDb ou das suas listas, com um botão para adicioná-lo a uma lista\n" +
+			"**Importante:** antes de dar `/add`, dê `/query` para ter certeza de que é o filme certo!",
+		"all.empty":           "A lista de filmes está vazia! Comece a adicionar filmes com /add!",
+		"all.header":          "Lista de filmes para assistir:\n",
+		"all.header.list":     "Lista de filmes para assistir (%s):\n",
+		"all.header.guests":   "Lista de filmes para assistir hoje (@%s):\n",
+		"all.votes.one":       " [%d voto]",
+		"all.votes.other":     " [%d votos]",
+		"all.footer":          "`/show i` - mostra mais sobre o `i`-ésimo filme.",
+		"all.grid":            "Lista de filmes para assistir",
+		"watched.unknown":     "Não sei quem é %s!",
+		"watched.pending":     "Filmes assistidos por %s ainda na lista para assistir:\n",
+		"watched.archived":    "Filmes assistidos por %s na lista de assistidos:\n",
+		"watched.total.one":   "Total: %d filme assistido",
+		"watched.total.other": "Total: %d filmes assistidos",
+		"watched.empty":       "Vocês ainda não assistiram nenhum filme! :(",
+		"watched.header":      "Lista de filmes assistidos:\n",
+		"draw.positive":       "Só consigo sortear um número positivo de filmes!",
+		"draw.none":           "Não achei nenhum filme que sirva! :(",
+		"draw.chosen.one":     "Escolhi este filme para vocês assistirem. Divirtam-se! :)",
+		"draw.chosen.other":   "Escolhi estes filmes para vocês assistirem. Divirtam-se! :)",
+		"draw.budget":         "Isso dá %s das suas %s.\n",
+		"draw.footer": "Vocês podem saber mais sobre cada filme com `/show i`, onde `i` é o número entre " +
+			"{chaves}. Não esqueçam de dar `/watch i` quando terminarem de assistir o filme `i`!",
+		"archived.one": "Tirei o filme abaixo porque todo mundo já assistiu!\n%s" +
+			"Para desfazer, me diga `/restore`.",
+		"archived.other": "Tirei os filmes abaixo porque todo mundo já assistiu!\n%s" +
+			"Para desfazer, me diga `/restore`.",
+		"language.current":        "Aqui eu falo %s. Mude com `/language código`, onde `código` é um de %s.",
+		"language.unknown":        "Não falo %s! Tente um de %s.",
+		"language.set":            "De agora em diante eu falo português aqui.",
+		"add.present":             "O filme já está na nossa lista para assistir!",
+		"add.suggest":             " (você quis dizer %s?)",
+		"add.added":               "Adicionados à nossa lista para assistir:\n",
+		"add.already":             "Já estavam na nossa lista para assistir:\n",
+		"add.missing":             "Não achei:\n",
+		"add.none":                "Não achei o que você buscou!",
+		"show.runtime":            " - %d min",
+		"show.released":           " - estreia %s",
+		"show.seasons.one":        ", %d temporada",
+		"show.seasons.other":      ", %d temporadas",
+		"show.directors":          "\nDirigido por %s",
+		"show.cast":               "\nCom %s",
+		"show.language":           "\nLíngua: %s",
+		"show.tags":               "\nTags: %s",
+		"show.rating":             "\nNota: %.1f/10.0",
+		"show.watched":            "\nAssistido por (%d):",
+		"show.progress":           "\nProgresso:",
+		"show.ratings":            "\nNossas notas:",
+		"show.notes":              "Notas",
+		"show.discussion":         "Conversa",
+		"remove.done":             "Tirando %s (%d) da lista de filmes...",
+		"watch.film":              "%s (%d) não é uma série!\n",
+		"watch.episode":           "%s (%d) não tem %s!\n",
+		"watch.next":              "Seu próximo em %s (%d): %s.\n",
+		"watch.finished":          "Você terminou %s (%d)!\n",
+		"rate.done":               "Você deu %[3]d/10 a %[1]s (%[2]d).",
+		"rate.usage":              "Uso: `/rate i nota` ou `/rate watched i nota`, onde `nota` vai de 1 a 10.",
+		"refresh.done.one":        "Atualizei os detalhes de %d de %d filme.",
+		"refresh.done.other":      "Atualizei os detalhes de %d de %d filmes.",
+		"ranking.header":          "Ranking de filmes assistidos:\n",
+		"pick.none":               "Nenhum filme da nossa lista se parece com %s!",
+		"pick.which":              "Qual deles você quis dizer?",
+		"note.usage":              "Qual filme? Escreva /note i texto, com i do /all.",
+		"note.none":               "Ainda não há notas sobre %s (%d)!",
+		"note.header":             "Notas sobre %s (%d):",
+		"note.done":               "Anotado em %s (%d).",
+		"tag.done":                "Marquei %s (%d) com %s.",
+		"tag.present":             "%s (%d) já tem essas tags.",
+		"tag.usage":               "Marque %s (%d) com /tag %d #tag.",
+		"tag.which":               "Qual tag você quis dizer?",
+		"untag.none":              "%s (%d) não tem tags!",
+		"untag.which":             "Qual tag devo tirar?",
+		"untag.missing":           "%s (%d) não tem nenhuma dessas tags!",
+		"untag.done":              "Tirei %s de %s (%d).",
+		"dedupe.changed":          "A lista mudou desde que perguntei! Tente `/dedupe` de novo.",
+		"dedupe.merged":           "Juntei %s (%d) {%d} a %s (%d) {%d}.",
+		"dedupe.same":             "Eles são o mesmo filme no IMDb, então só posso juntá-los.",
+		"dedupe.kept":             "Entendi, %s (%d) e %s (%d) são filmes diferentes.",
+		"dedupe.none":             "Não há repetidos na nossa lista!",
+		"dedupe.ask":              "%s (%d) {%d} e %s (%d) {%d} parecem o mesmo filme. Junto o segundo ao primeiro?",
+		"dedupe.merge":            "Juntar",
+		"dedupe.keep":             "Manter os dois",
+		"offer.stream":            "Streaming",
+		"offer.free":              "Grátis",
+		"offer.ads":               "Grátis com anúncios",
+		"offer.rent":              "Aluguel",
+		"offer.buy":               "Compra",
+		"country.current":         "Procuro onde assistir filmes em %s. Mude com `/country código`, ex. `/country BR`.",
+		"country.unknown":         "%s não é um código de país! Tente um de duas letras, como `BR` ou `US`.",
+		"country.set":             "Vou procurar onde assistir filmes em %s.",
+		"notify.released":         "%s (%d) estreou!\n",
+		"notify.offered":          "%s (%d) agora está em %s!\n",
+		"notify.services.none":    "Não vou mais acompanhar serviços de streaming.",
+		"notify.services":         "Vou avisar quem quiser saber quando nossos filmes chegarem a %s.",
+		"notify.services.unknown": " Mas ainda não sei onde os filmes estão passando!",
+		"notify.off":              "Não vou mais te avisar de estreias.",
+		"notify.on": "Vou te avisar quando filmes da nossa lista estrearem. " +
+			"Envie `/notify` de novo para parar.",
+		"notify.on.services": "Vou te avisar quando filmes da nossa lista estrearem ou chegarem a %s. " +
+			"Envie `/notify` de novo para parar.",
+		"private.unknown":   "Não sei quem você é!",
+		"groups.private":    "Fale comigo no privado para ver seus grupos!",
+		"groups.none":       "Ainda não vi você em nenhum grupo! Diga algo primeiro num grupo em que eu esteja.",
+		"groups.header":     "Seus grupos:\n",
+		"groups.item.one":   "  %d. %s - %d filme, %d que você não viu\n",
+		"groups.item.other": "  %d. %s - %d filmes, %d que você não viu\n",
+		"groups.footer": "`/mine` lista o que você não viu em todos eles, e `/addto i título` adiciona um " +
+			"filme ao `i`-ésimo grupo sem incomodar ninguém.",
+		"mine.private":    "Fale comigo no privado para ver seus filmes de todos os grupos!",
+		"mine.own":        "Sua própria lista:\n",
+		"mine.none":       "Você já viu tudo nas suas listas!",
+		"addto.private":   "Fale comigo no privado para adicionar filmes aos seus grupos em silêncio!",
+		"addto.usage":     "Diga qual grupo e qual filme, ex. `/addto 0 título`. Veja seus grupos com `/groups`.",
+		"addto.group":     "Você não está nesse grupo! Veja seus grupos com `/groups`.",
+		"addto.present":   "%s (%d) já está na lista de %s!",
+		"addto.done":      "Adicionei %s (%d) à lista de %s.",
+		"addto.button":    "Adicionar a %s",
+		"addto.offer":     "Adicionar à lista de um grupo também?",
+		"list.unknown":    "Não há uma lista chamada %s! Veja suas listas com `/list`.",
+		"list.present":    "%s (%d) já está em %s!\n",
+		"list.moved":      "Movi %s (%d) para %s.\n",
+		"list.header":     "Suas listas:\n",
+		"list.item.one":   "  %s - %d filme",
+		"list.item.other": "  %s - %d filmes",
+		"list.current":    " (em uso)",
+		"list.footer": "`/list switch nome` troca de lista, e `list:nome` faz qualquer comando " +
+			"usar outra lista.",
+		"list.name":              "Nomes de lista só podem ter letras, dígitos, `-` e `_`!",
+		"list.exists":            "Já existe uma lista chamada %s!",
+		"list.created":           "Criei a lista %[1]s. Use-a com `/list switch %[1]s`.",
+		"list.rename.refused":    "Não posso renomear %s!",
+		"list.rename.failed":     "Não consegui renomear %s!",
+		"list.renamed":           "Renomeei %s para %s.",
+		"list.delete.refused":    "Não posso apagar %s!",
+		"list.delete.full.one":   "%s ainda tem %d filme! Mova ou tire ele antes.",
+		"list.delete.full.other": "%s ainda tem %d filmes! Mova ou tire eles antes.",
+		"list.deleted":           "Apaguei a lista %s.",
+		"list.missing":           "Não há uma lista chamada %s!",
+		"list.switched.one":      "Agora usando %s (%d filme).",
+		"list.switched.other":    "Agora usando %s (%d filmes).",
+		"list.move.refused":      "Não posso mover filmes para %s!",
+		"list.usage": "Tente `/list`, `/list create nome`, `/list rename antigo novo`, `/list delete nome`, " +
+			"`/list switch nome` ou `/list move nome i1 i2 ...`.",
+		"tonight.ask":       "Quem vai assistir hoje?",
+		"tonight.sofar":     " Até agora: @%s.",
+		"tonight.in":        "Tô dentro!",
+		"tonight.out":       "Tô fora",
+		"tonight.none":      "Ninguém vai assistir hoje. `/all`, `/draw` e `/top` voltaram ao normal.",
+		"tonight.set":       "Assistindo hoje: @%s. `/all`, `/draw` e `/top` só mostram o que menos de vocês viram.",
+		"vote.removed":      "Você tirou seu voto em %s (%d).\n",
+		"vote.added":        "Você votou em %s (%d).\n",
+		"top.header":        "Filmes mais votados:\n",
+		"top.header.guests": "Filmes mais votados para hoje (@%s):\n",
+		"top.item.one":      "  %d. %s (%d) {%d} - %d voto\n",
+		"top.item.other":    "  %d. %s (%d) {%d} - %d votos\n",
+		"top.none":          "Ninguém votou ainda! Vote em filmes com `/vote i`.",
+		"recommend.none": "Ainda não sei o bastante do que vocês gostam! Assistam e deem `/rate` em " +
+			"alguns filmes antes.",
+		"recommend.pending": "Ainda estou lendo sobre filmes de que vocês podem gostar. " +
+			"Me perguntem de novo em um minuto!",
+		"recommend.header":   "Vocês podem gostar de:\n",
+		"recommend.because":  "\n     porque gostaram de %s (%d)\n",
+		"recommend.footer":   "Adicione um com `/add título`.",
+		"log.add":            "adicionou %s",
+		"log.remove":         "tirou %s",
+		"log.watch":          "assistiu %s [%s -> %s]",
+		"log.unwatch":        "desmarcou %s como assistido [%s -> %s]",
+		"log.tag":            "marcou %s [%s -> %s]",
+		"log.untag":          "desmarcou %s [%s -> %s]",
+		"log.note":           "deixou uma nota em %s",
+		"log.comment":        "comentou sobre %s",
+		"log.merge":          "juntou um repetido a %s",
+		"log.archive":        "arquivou %s (todos assistiram)",
+		"log.restore":        "devolveu %s",
+		"log.import":         "importou %s",
+		"log.episode":        "assistiu %s de %s",
+		"log.move":           "moveu %s para outra lista",
+		"log.vote":           "votou em %s",
+		"log.unvote":         "tirou o voto em %s",
+		"log.rate":           "deu %[2]d/10 a %[1]s",
+		"log.empty":          "Nada aconteceu com a lista ainda!",
+		"log.usage":          "Uso: `/log página`, onde `página` é um número positivo.",
+		"log.header":         "Mudanças na lista (página %d/%d):\n",
+		"log.older":          "Mudanças mais antigas: `/log %d`. ",
+		"log.footer":         "Histórico completo: `/log export`.",
+		"show.progress.next": "\n  @%s: assistiu %s, próximo %s",
+		"show.progress.done": "\n  @%s: terminou",
+		"import.usage": "Me envie um CSV exportado do IMDb ou do Letterboxd, ou um arquivo de texto com " +
+			"um título por linha, com `/import` na legenda (ou responda ao arquivo com `/import`). `/import undo` " +
+			"tira tudo o que a última importação adicionou.",
+		"import.big":           "Esse arquivo é grande demais para mim!",
+		"import.download":      "Não consegui baixar esse arquivo!",
+		"import.empty":         "Não achei nenhum filme nesse arquivo!",
+		"import.done.one":      "Importei %d filme, %d já estavam na nossa lista para assistir.\n",
+		"import.done.other":    "Importei %d filmes, %d já estavam na nossa lista para assistir.\n",
+		"import.missing.one":   "Não achei este %d:\n",
+		"import.missing.other": "Não achei estes %d:\n",
+		"import.row":           "  linha %d: %s\n",
+		"import.more":          "  …e mais %d\n",
+		"import.undo":          "Mudou de ideia? `/import undo`",
+		"import.undo.none":     "Não há importação para desfazer!",
+		"import.undone.one":    "Tirei %d filme importado da lista para assistir.",
+		"import.undone.other":  "Tirei %d filmes importados da lista para assistir.",
+		"filter.rating":        "%s não é uma nota",
+		"filter.decade":        "%s não é uma década",
+		"filter.duration":      "%s não é uma duração",
+		"filter.key":           "Não sei filtrar por %s",
+		"filter.on":            "Não sei onde os filmes estão passando",
+		"export.usage":         "Uso: `/export csv`, `/export json` ou `/export letterboxd usuário`.",
+		"inline.add":           "Adicionar a uma lista",
+		"inline.list":          "Na sua lista para assistir",
+		"inline.imdb":          "Do IMDb",
+		"start.none":           "Não achei esse filme no IMDb!",
+		"grid.more":            "\n(só os primeiros %d couberam na imagem)",
+	},
+}
@@ -7,25 +7,57 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"time"
 )
 
 type Chat struct {
+	id            int64
 	prefix        string
+	list          string
 	movies        []Entry
 	undoMovies    []Entry
+	undoList      string
 	watchedMovies []Entry
 	lastQuery     string
 	allUsers      map[string]*tgbotapi.User
+	members       map[int]bool
+	changes       []Change
+	lastImport    []string
+	importList    string
+	lastDrawn     map[string]time.Time
+	tonight       Audience
+	settings      Settings
+	cards         map[int]string
+	alerts        Alerts
+}
+
+// Settings are a chat's preferences, along with what we know about the chat itself.
+type Settings struct {
+	Title    string `json:",omitempty"`
+	List     string `json:",omitempty"`
+	Country  string `json:",omitempty"`
+	Language string `json:",omitempty"`
+	// Distinct holds the pairs of IMDb IDs of movies told apart with /dedupe.
+	Distinct []string `json:",omitempty"`
 }
 
 var chatMap map[int64]*Chat = make(map[int64]*Chat)
 
 func chat(u *tgbotapi.Update) *Chat {
-	id := u.Message.Chat.ID
+	C := chatByID(u.Message.Chat.ID)
+	if t := u.Message.Chat.Title; t != "" && t != C.settings.Title {
+		C.settings.Title = t
+		saveSettings(C)
+	}
+	return C
+}
+
+func chatByID(id int64) *Chat {
 	var C *Chat
 	var e bool
 	if C, e = chatMap[id]; !e {
-		C = &Chat{fmt.Sprintf("chat%d/", id), nil, nil, nil, "", make(map[string]*tgbotapi.User)}
+		C = &Chat{id: id, prefix: fmt.Sprintf("chat%d/", id), list: defaultList,
+			allUsers: make(map[string]*tgbotapi.User), members: make(map[int]bool)}
 		var newChat bool
 		if _, err := os.Stat(C.prefix); os.IsNotExist(err) {
 			err = os.Mkdir(C.prefix, os.ModePerm)
@@ -34,10 +66,22 @@ func chat(u *tgbotapi.Update) *Chat {
 			}
 			newChat = true
 		} else {
-			loadList(C.prefix+"movies.json", &C.movies)
+			loadSettings(C)
+			if C.settings.List != "" && hasList(C, C.settings.List) {
+				C.list = C.settings.List
+			}
+			loadList(listPath(C, C.list), &C.movies)
 			loadList(C.prefix+"watched.json", &C.watchedMovies)
-			loadList(C.prefix+"undo.json", &C.undoMovies)
+			loadUndo(C)
 			loadUsers(C)
+			loadMembers(C)
+			loadLog(C)
+			loadImport(C)
+			loadDraws(C)
+			loadTonight(C)
+			loadCards(C)
+			loadAlerts(C)
+			fillAddedBy(C)
 		}
 		chatMap[id] = C
 		if newChat {
@@ -47,6 +91,23 @@ func chat(u *tgbotapi.Update) *Chat {
 	return C
 }
 
+// loadAllChats loads every chat we have stored, so private chats can reach the groups their
+// users are in before those groups say anything.
+func loadAllChats() {
+	D, err := ioutil.ReadDir(".")
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	for _, d := range D {
+		var id int64
+		if _, err := fmt.Sscanf(d.Name(), "chat%d", &id); err == nil && d.IsDir() {
+			chatByID(id)
+		}
+	}
+	log.Printf("Loaded %d chats", len(chatMap))
+}
+
 func saveChats() {
 	f, err := os.Create("chats.json")
 	if err != nil {
@@ -90,3 +151,42 @@ func loadChats() {
 		log.Printf("Error: %v", err)
 	}
 }
+
+func saveSettings(C *Chat) {
+	f, err := os.Create(C.prefix + "settings.json")
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	defer f.Close()
+	b, err := json.Marshal(C.settings)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	_, err = f.Write(b)
+	if err != nil {
+		log.Printf("Error: %v", err)
+	}
+}
+
+func loadSettings(C *Chat) {
+	f, err := os.Open(C.prefix + "settings.json")
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	if len(b) < 5 {
+		return
+	}
+	err = json.Unmarshal(b, &C.settings)
+	if err != nil {
+		log.Printf("Error: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/nfnt/resize"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"image"
+	"image/color"
+	"image/draw"
+	"log"
+	"strings"
+	"sync"
+)
+
+const OptGrid = "grid"
+
+const (
+	thumbWidth     = 200
+	thumbHeight    = 296
+	labelHeight    = 20
+	collageColumns = 4
+	maxCollage     = 24
+)
+
+var (
+	tileBackground  = color.RGBA{40, 40, 40, 255}
+	labelBackground = color.RGBA{0, 0, 0, 180}
+)
+
+// hasOption removes opt from the arguments of a command, returning the remaining arguments and
+// whether opt was there.
+func hasOption(args, opt string) (string, bool) {
+	F := strings.Fields(args)
+	for i, f := range F {
+		if strings.ToLower(f) == opt {
+			return strings.Join(append(F[:i], F[i+1:]...), " "), true
+		}
+	}
+	return args, false
+}
+
+// label draws s over the bottom of tile r of I, cut short to fit its width. The font only has
+// ASCII glyphs, so accents are stripped.
+func label(I draw.Image, r image.Rectangle, s string) {
+	face := basicfont.Face7x13
+	bar := image.Rect(r.Min.X, r.Max.Y-labelHeight, r.Max.X, r.Max.Y)
+	draw.Draw(I, bar, image.NewUniform(labelBackground), image.Point{}, draw.Over)
+	R := []rune(ascii(s))
+	if max := (r.Dx() - 8) / face.Advance; len(R) > max {
+		R = append(R[:max-2], '.', '.')
+	}
+	d := font.Drawer{
+		Dst:  I,
+		Src:  image.White,
+		Face: face,
+		Dot:  fixed.P(r.Min.X+4, r.Max.Y-labelHeight+face.Ascent+(labelHeight-face.Height)/2),
+	}
+	d.DrawString(string(R))
+}
+
+// Collage renders the covers of E as a grid, each labelled with the matching string of labels,
+// and returns it encoded as a JPEG. Covers that cannot be fetched are left blank.
+func Collage(E []Entry, labels []string) ([]byte, error) {
+	n := len(E)
+	cols := collageColumns
+	if n < cols {
+		cols = n
+	}
+	rows := (n + cols - 1) / cols
+	I := image.NewRGBA(image.Rect(0, 0, cols*thumbWidth, rows*thumbHeight))
+	draw.Draw(I, I.Bounds(), image.NewUniform(tileBackground), image.Point{}, draw.Src)
+	T := make([]image.Image, n)
+	var wg sync.WaitGroup
+	for i := range E {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			img, _, _, err := GetImage(E[i].ID, E[i].Cover)
+			if err != nil {
+				log.Printf("Error: %v", err)
+				return
+			}
+			T[i] = resize.Thumbnail(thumbWidth, thumbHeight, img, resize.Lanczos3)
+		}(i)
+	}
+	wg.Wait()
+	for i := range E {
+		r := image.Rect(0, 0, thumbWidth, thumbHeight).Add(image.Pt((i%cols)*thumbWidth, (i/cols)*thumbHeight))
+		if T[i] != nil {
+			b := T[i].Bounds()
+			off := image.Pt((thumbWidth-b.Dx())/2, (thumbHeight-b.Dy())/2)
+			draw.Draw(I, b.Sub(b.Min).Add(r.Min).Add(off), T[i], b.Min, draw.Src)
+		}
+		label(I, r, labels[i])
+	}
+	return Encode(I, maxJPEGQuality)
+}
+
+// sendCollage replies to u with a collage of the covers of E, or returns false if it could not.
+func sendCollage(bot *tgbotapi.BotAPI, u *tgbotapi.Update, E []Entry, labels []string, caption string) bool {
+	if len(E) == 0 {
+		return false
+	}
+	if len(E) > maxCollage {
+		E, labels = E[:maxCollage], labels[:maxCollage]
+		caption += chat(u).T("grid.more", maxCollage)
+	}
+	b, err := Collage(E, labels)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return false
+	}
+	msg := tgbotapi.NewPhotoUpload(u.Message.Chat.ID, tgbotapi.FileBytes{Name: "collage.jpg", Bytes: b})
+	msg.Caption = caption
+	msg.ReplyToMessageID = u.Message.MessageID
+	if _, err = bot.Send(msg); err != nil {
+		log.Printf("Error: %v", err)
+		return false
+	}
+	return true
+}
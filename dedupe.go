@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const CmdDedupe = "dedupe"
+
+const (
+	OptMerge = "merge"
+	OptKeep  = "keep"
+)
+
+// maxDuplicates is how many pairs of duplicates /dedupe asks about at once.
+const maxDuplicates = 5
+
+// isDuplicate returns whether a and b are likely the same movie: either they have the same IMDb
+// ID, or, unless they were told apart before, the same title or similar titles from about the
+// same year.
+func isDuplicate(C *Chat, a, b *Entry) bool {
+	if a.ID != "" && a.ID == b.ID {
+		return true
+	}
+	if inList(distinctKey(a, b), C.settings.Distinct) {
+		return false
+	}
+	d := a.Year - b.Year
+	return normalise(a.Title) == normalise(b.Title) ||
+		(d >= -1 && d <= 1 && (matchScore(a.Title, b.Title) >= 0 || matchScore(b.Title, a.Title) >= 0))
+}
+
+// distinctKey identifies the pair of movies a and b, in any order.
+func distinctKey(a, b *Entry) string {
+	if a.ID > b.ID {
+		a, b = b, a
+	}
+	return a.ID + "," + b.ID
+}
+
+// duplicates returns the pairs of indices of movies in C's list that are likely the same.
+func duplicates(C *Chat) [][2]int {
+	var D [][2]int
+	for i := range C.movies {
+		for j := i + 1; j < len(C.movies); j++ {
+			if isDuplicate(C, &C.movies[i], &C.movies[j]) {
+				D = append(D, [2]int{i, j})
+			}
+		}
+	}
+	return D
+}
+
+// union appends the strings of B not in A to A.
+func union(A, B []string) []string {
+	for _, b := range B {
+		if !inList(b, A) {
+			A = append(A, b)
+		}
+	}
+	return A
+}
+
+// merge merges what members did with b into a.
+func merge(a, b *Entry) {
+	a.WatchedBy = union(a.WatchedBy, b.WatchedBy)
+	a.Votes = union(a.Votes, b.Votes)
+	a.Tags = union(a.Tags, b.Tags)
+	for usr, r := range b.Ratings {
+		if _, e := a.Ratings[usr]; !e {
+			if a.Ratings == nil {
+				a.Ratings = make(map[string]int)
+			}
+			a.Ratings[usr] = r
+		}
+	}
+	for usr, ep := range b.Progress {
+		p, e := a.Progress[usr]
+		if !e || p.Season < ep.Season || (p.Season == ep.Season && p.Episode < ep.Episode) {
+			if a.Progress == nil {
+				a.Progress = make(map[string]Episode)
+			}
+			a.Progress[usr] = ep
+		}
+	}
+	a.Notes = byTime(append(a.Notes, b.Notes...))
+	a.Comments = byTime(append(a.Comments, b.Comments...))
+}
+
+// byTime sorts L from the oldest comment to the newest.
+func byTime(L []Comment) []Comment {
+	sort.SliceStable(L, func(i, j int) bool { return L[i].Time.Before(L[j].Time) })
+	return L
+}
+
+func Dedupe(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	C := chat(u)
+	args := strings.Fields(u.Message.CommandArguments())
+	if len(args) == 3 && (args[0] == OptMerge || args[0] == OptKeep) {
+		i, erri := strconv.Atoi(args[1])
+		j, errj := strconv.Atoi(args[2])
+		var s string
+		if erri != nil || errj != nil || i < 0 || j <= i || j >= len(C.movies) ||
+			!isDuplicate(C, &C.movies[i], &C.movies[j]) {
+			s = C.H("dedupe.changed")
+		} else if a, b := &C.movies[i], &C.movies[j]; args[0] == OptMerge {
+			before := snapshot(a)
+			merge(a, b)
+			record(C, u, ActMerge, before, a)
+			s = C.H("dedupe.merged", escape(b.Title), b.Year, j, escape(a.Title), a.Year, i)
+			C.movies = append(C.movies[:j], C.movies[j+1:]...)
+			saveMovies(C)
+		} else if a.ID == b.ID {
+			s = C.H("dedupe.same")
+		} else {
+			C.settings.Distinct = append(C.settings.Distinct, distinctKey(a, b))
+			saveSettings(C)
+			s = C.H("dedupe.kept", escape(a.Title), a.Year, escape(b.Title), b.Year)
+		}
+		msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
+		msg.ReplyToMessageID = u.Message.MessageID
+		sendHTML(bot, msg)
+		return
+	}
+	D := duplicates(C)
+	if len(D) == 0 {
+		msg := tgbotapi.NewMessage(u.Message.Chat.ID, C.T("dedupe.none"))
+		msg.ReplyToMessageID = u.Message.MessageID
+		bot.Send(msg)
+		return
+	}
+	for k, d := range D {
+		if k == maxDuplicates {
+			break
+		}
+		a, b := &C.movies[d[0]], &C.movies[d[1]]
+		msg := tgbotapi.NewMessage(u.Message.Chat.ID, C.T("dedupe.ask", a.Title, a.Year, d[0],
+			b.Title, b.Year, d[1]))
+		data := func(opt string) string {
+			return fmt.Sprintf("%s %s %d %d%s", CmdDedupe, opt, d[0], d[1], listArg(C))
+		}
+		row := tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(C.T("dedupe.merge"), data(OptMerge)))
+		if a.ID != b.ID {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(C.T("dedupe.keep"), data(OptKeep)))
+		}
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(row)
+		msg.ReplyToMessageID = u.Message.MessageID
+		bot.Send(msg)
+	}
+}
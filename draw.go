@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"time"
+)
+
+// OptAgain makes /draw consider movies drawn recently too.
+const OptAgain = "again"
+
+// recentDraw is for how long a drawn movie is left out of later draws.
+const recentDraw = 7 * 24 * time.Hour
+
+// drawnRecently returns whether the movie with IMDb ID id was drawn within recentDraw.
+func drawnRecently(C *Chat, id string) bool {
+	t, e := C.lastDrawn[id]
+	return e && time.Since(t) < recentDraw
+}
+
+// markDrawn remembers the movies of E were drawn now, forgetting draws that are no longer
+// recent.
+func markDrawn(C *Chat, E []Entry) {
+	if C.lastDrawn == nil {
+		C.lastDrawn = make(map[string]time.Time)
+	}
+	for id, t := range C.lastDrawn {
+		if time.Since(t) >= recentDraw {
+			delete(C.lastDrawn, id)
+		}
+	}
+	for _, e := range E {
+		C.lastDrawn[e.ID] = time.Now()
+	}
+	saveDraws(C)
+}
+
+func saveDraws(C *Chat) {
+	f, err := os.Create(C.prefix + "draws.json")
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	defer f.Close()
+	b, err := json.Marshal(C.lastDrawn)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	_, err = f.Write(b)
+	if err != nil {
+		log.Printf("Error: %v", err)
+	}
+}
+
+func loadDraws(C *Chat) {
+	f, err := os.Open(C.prefix + "draws.json")
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	if len(b) < 5 {
+		return
+	}
+	err = json.Unmarshal(b, &C.lastDrawn)
+	if err != nil {
+		log.Printf("Error: %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const CmdExport = "export"
+
+const (
+	FormatCSV        = "csv"
+	FormatJSON       = "json"
+	FormatLetterboxd = "letterboxd"
+)
+
+// Viewing is a single movie watched by a user.
+type Viewing struct {
+	Title       string
+	Year        int
+	ID          string
+	WatchedDate string `json:",omitempty"`
+	Rating      int    `json:",omitempty"`
+}
+
+// watchDate returns when user last marked the movie with IMDb ID id as watched, according to
+// the chat's audit log.
+func watchDate(C *Chat, id, user string) (time.Time, bool) {
+	for i := len(C.changes) - 1; i >= 0; i-- {
+		c := &C.changes[i]
+		if c.Action == ActWatch && c.After != nil && c.After.ID == id && strings.EqualFold(c.User, user) {
+			return c.Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func hasWatched(m *Entry, user string) bool {
+	for _, w := range m.WatchedBy {
+		if strings.EqualFold(w, user) {
+			return true
+		}
+	}
+	return false
+}
+
+func userRating(m *Entry, user string) int {
+	for w, r := range m.Ratings {
+		if strings.EqualFold(w, user) {
+			return r
+		}
+	}
+	return 0
+}
+
+// history returns every movie user has watched, both in the to-watch and watched lists.
+func history(C *Chat, user string, F Filter) []Viewing {
+	var H []Viewing
+	for _, L := range [][]Entry{F.Select(C.watchedMovies), F.Select(C.movies)} {
+		for i := range L {
+			m := &L[i]
+			if !hasWatched(m, user) {
+				continue
+			}
+			v := Viewing{Title: m.Title, Year: m.Year, ID: m.ID, Rating: userRating(m, user)}
+			if t, ok := watchDate(C, m.ID, user); ok {
+				v.WatchedDate = t.Format("2006-01-02")
+			}
+			H = append(H, v)
+		}
+	}
+	return H
+}
+
+func exportJSON(C *Chat, F Filter) ([]byte, error) {
+	H := make(map[string][]Viewing)
+	for s, usr := range C.allUsers {
+		if h := history(C, s, F); h != nil {
+			H[usr.UserName] = h
+		}
+	}
+	return json.MarshalIndent(struct {
+		ToWatch []Entry
+		Watched []Entry
+		History map[string][]Viewing
+	}{F.Select(C.movies), F.Select(C.watchedMovies), H}, "", "  ")
+}
+
+func exportCSV(C *Chat, F Filter) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+	w.Write([]string{"List", "Title", "Year", "imdbID", "User", "WatchedDate", "Rating"})
+	for _, l := range []struct {
+		name string
+		L    []Entry
+	}{{"to-watch", F.Select(C.movies)}, {"watched", F.Select(C.watchedMovies)}} {
+		for i := range l.L {
+			m := &l.L[i]
+			row := []string{l.name, m.Title, strconv.Itoa(m.Year), m.ID}
+			if len(m.WatchedBy) == 0 {
+				w.Write(append(row, "", "", ""))
+				continue
+			}
+			for _, usr := range m.WatchedBy {
+				var d, r string
+				if t, ok := watchDate(C, m.ID, usr); ok {
+					d = t.Format("2006-01-02")
+				}
+				if v := userRating(m, usr); v > 0 {
+					r = strconv.Itoa(v)
+				}
+				w.Write(append(row, usr, d, r))
+			}
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// exportLetterboxd writes user's history in Letterboxd's import format. Letterboxd rates from
+// 0.5 to 5 stars, so our 1 to 10 ratings are halved.
+func exportLetterboxd(C *Chat, user string, F Filter) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+	w.Write([]string{"Title", "Year", "imdbID", "WatchedDate", "Rating"})
+	for _, v := range history(C, user, F) {
+		var r string
+		if v.Rating > 0 {
+			r = strconv.FormatFloat(float64(v.Rating)/2, 'f', -1, 64)
+		}
+		w.Write([]string{v.Title, strconv.Itoa(v.Year), v.ID, v.WatchedDate, r})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func Export(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	C := chat(u)
+	F, rest, err := parseFilter(C, u.Message.CommandArguments())
+	if err != nil {
+		msg := tgbotapi.NewMessage(u.Message.Chat.ID, C.explain(err)+"!")
+		msg.ReplyToMessageID = u.Message.MessageID
+		bot.Send(msg)
+		return
+	}
+	args := strings.Fields(strings.ToLower(rest))
+	format := FormatCSV
+	if len(args) > 0 {
+		format = args[0]
+	}
+	var b []byte
+	var name string
+	switch format {
+	case FormatCSV:
+		b, err = exportCSV(C, F)
+		name = "movies.csv"
+	case FormatJSON:
+		b, err = exportJSON(C, F)
+		name = "movies.json"
+	case FormatLetterboxd:
+		usr := u.Message.From.UserName
+		if len(args) > 1 {
+			usr = strings.TrimPrefix(args[1], "@")
+			if _, e := C.User(usr); !e {
+				msg := tgbotapi.NewMessage(u.Message.Chat.ID, C.T("watched.unknown", usr))
+				msg.ReplyToMessageID = u.Message.MessageID
+				bot.Send(msg)
+				return
+			}
+		}
+		b, err = exportLetterboxd(C, usr, F)
+		name = fmt.Sprintf("letterboxd-%s.csv", strings.ToLower(usr))
+	default:
+		msg := tgbotapi.NewMessage(u.Message.Chat.ID, C.H("export.usage"))
+		msg.ReplyToMessageID = u.Message.MessageID
+		sendHTML(bot, msg)
+		return
+	}
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	msg := tgbotapi.NewDocumentUpload(u.Message.Chat.ID, tgbotapi.FileBytes{Name: name, Bytes: b})
+	msg.ReplyToMessageID = u.Message.MessageID
+	bot.Send(msg)
+}
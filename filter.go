@@ -0,0 +1,266 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// filterError is a mistake in a filter, told by the message key of the catalog formatted with
+// args, so that it can be told in each chat's language.
+type filterError struct {
+	key  string
+	args []interface{}
+}
+
+func (e *filterError) Error() string {
+	return format(catalog[LangEnglish][e.key], e.args)
+}
+
+func filterErrorf(key string, args ...interface{}) error {
+	return &filterError{key, args}
+}
+
+// explain tells err in C's language.
+func (C *Chat) explain(err error) string {
+	if e, ok := err.(*filterError); ok {
+		return C.T(e.key, e.args...)
+	}
+	return err.Error()
+}
+
+// cond is a single condition of a Filter.
+type cond func(e *Entry) bool
+
+// Filter is a conjunction of conditions on entries, written in command arguments as
+// space-separated `key:value` terms, e.g. `genre:horror decade:1980s`.
+type Filter []cond
+
+// filterKeys maps every filter key to the function that parses its value into a condition.
+var filterKeys = map[string]func(v string) (cond, error){
+	"genre":    anyOf(func(e *Entry) []string { return e.Genres }),
+	"director": anyOf(func(e *Entry) []string { return e.Directors }),
+	"actor":    anyOf(func(e *Entry) []string { return e.Cast }),
+	"lang": func(v string) (cond, error) {
+		return func(e *Entry) bool { return matchScore(v, e.Language) >= 0 }, nil
+	},
+	"cert": func(v string) (cond, error) {
+		return func(e *Entry) bool { return strings.EqualFold(v, e.Certificate) }, nil
+	},
+	"decade": func(v string) (cond, error) {
+		d, err := parseDecade(v)
+		if err != nil {
+			return nil, err
+		}
+		return func(e *Entry) bool { return e.Year >= d && e.Year < d+10 }, nil
+	},
+	"rating": func(v string) (cond, error) {
+		r, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, filterErrorf("filter.rating", v)
+		}
+		return func(e *Entry) bool { return e.IMDbRating >= r }, nil
+	},
+	"unwatched-by": func(v string) (cond, error) {
+		U := usernames(v)
+		return func(e *Entry) bool {
+			for _, usr := range U {
+				if hasWatched(e, usr) {
+					return false
+				}
+			}
+			return true
+		}, nil
+	},
+	"added-by": func(v string) (cond, error) {
+		U := usernames(v)
+		return func(e *Entry) bool {
+			for _, usr := range U {
+				if strings.EqualFold(usr, e.AddedBy) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	},
+	"tag": func(v string) (cond, error) {
+		T := strings.Split(v, ",")
+		for i := range T {
+			T[i] = strings.TrimPrefix(strings.TrimSpace(T[i]), "#")
+		}
+		return func(e *Entry) bool {
+			for _, t := range T {
+				if hasTag(e, t) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	},
+	"runtime": func(v string) (cond, error) {
+		r, err := parseDuration(v)
+		if err != nil {
+			return nil, err
+		}
+		return func(e *Entry) bool { return e.Runtime > 0 && e.Runtime <= r }, nil
+	},
+}
+
+// anyOf returns a parser of conditions that hold when some string of an entry's field matches
+// any of the comma-separated alternatives of the value.
+func anyOf(field func(e *Entry) []string) func(v string) (cond, error) {
+	return func(v string) (cond, error) {
+		V := strings.Split(v, ",")
+		return func(e *Entry) bool {
+			for _, s := range field(e) {
+				for _, w := range V {
+					if matchScore(w, s) >= 0 {
+						return true
+					}
+				}
+			}
+			return false
+		}, nil
+	}
+}
+
+// usernames splits a comma-separated list of usernames, with or without @.
+func usernames(v string) []string {
+	var U []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimPrefix(strings.TrimSpace(s), "@"); s != "" {
+			U = append(U, s)
+		}
+	}
+	return U
+}
+
+// parseDecade reads decades written as 1980s, 1980 or 80s.
+func parseDecade(v string) (int, error) {
+	d, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(v), "s"))
+	if err != nil {
+		return 0, filterErrorf("filter.decade", v)
+	}
+	if d < 100 {
+		d += 1900
+	}
+	return d - d%10, nil
+}
+
+// parseDuration reads a duration in minutes written as 120, 120m, 2h or 2h30m.
+func parseDuration(v string) (int, error) {
+	v = strings.ToLower(v)
+	if m, err := strconv.Atoi(v); err == nil {
+		return m, nil
+	}
+	var m int
+	if i := strings.Index(v, "h"); i >= 0 {
+		h, err := strconv.ParseFloat(v[:i], 64)
+		if err != nil {
+			return 0, filterErrorf("filter.duration", v)
+		}
+		m = int(h * 60)
+		v = v[i+1:]
+	}
+	if v = strings.TrimSuffix(strings.TrimSuffix(v, "min"), "m"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, filterErrorf("filter.duration", v)
+		}
+		m += n
+	}
+	return m, nil
+}
+
+// formatDuration writes m minutes as hours and minutes.
+func formatDuration(m int) string {
+	if m < 60 {
+		return fmt.Sprintf("%dm", m)
+	}
+	if m%60 == 0 {
+		return fmt.Sprintf("%dh", m/60)
+	}
+	return fmt.Sprintf("%dh%02dm", m/60, m%60)
+}
+
+// takeBudget reads a total runtime budget written as `time=3h` or `max=120m` out of args,
+// returning the other arguments and the budget in minutes, or zero if there is none.
+func takeBudget(args string) (string, int, error) {
+	var rest []string
+	var b int
+	for _, a := range strings.Fields(args) {
+		i := strings.IndexAny(a, ":=")
+		if i < 0 {
+			rest = append(rest, a)
+			continue
+		}
+		if k := strings.ToLower(a[:i]); k != "time" && k != "max" {
+			rest = append(rest, a)
+			continue
+		}
+		m, err := parseDuration(a[i+1:])
+		if err != nil {
+			return "", 0, err
+		}
+		b = m
+	}
+	return strings.Join(rest, " "), b, nil
+}
+
+// chatFilterKeys maps the filter keys whose conditions depend on the chat, such as on its
+// country, to the function that parses their value.
+var chatFilterKeys = map[string]func(C *Chat, v string) (cond, error){
+	"on": onService,
+}
+
+// parseFilter reads the filter terms out of args, returning the filter and the other arguments.
+func parseFilter(C *Chat, args string) (Filter, string, error) {
+	var F Filter
+	var rest []string
+	for _, a := range strings.Fields(args) {
+		i := strings.IndexAny(a, ":=")
+		if i < 0 {
+			rest = append(rest, a)
+			continue
+		}
+		k, v := strings.ToLower(a[:i]), a[i+1:]
+		var c cond
+		var err error
+		if p, e := filterKeys[k]; e {
+			c, err = p(v)
+		} else if p, e := chatFilterKeys[k]; e {
+			c, err = p(C, v)
+		} else {
+			return nil, "", filterErrorf("filter.key", k)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		F = append(F, c)
+	}
+	return F, strings.Join(rest, " "), nil
+}
+
+// Select returns the entries of L that satisfy F.
+func (F Filter) Select(L []Entry) []Entry {
+	if len(F) == 0 {
+		return L
+	}
+	var S []Entry
+	for i := range L {
+		if F.Match(&L[i]) {
+			S = append(S, L[i])
+		}
+	}
+	return S
+}
+
+// Match returns whether e satisfies every condition of F.
+func (F Filter) Match(e *Entry) bool {
+	for _, c := range F {
+		if !c(e) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package main
+
+import "testing"
+
+func TestParseDecade(t *testing.T) {
+	tests := []struct {
+		v    string
+		want int
+		ok   bool
+	}{
+		{"1980s", 1980, true},
+		{"1985", 1980, true},
+		{"80s", 1980, true},
+		{"80S", 1980, true},
+		{"2010s", 2010, true},
+		{"eighties", 0, false},
+	}
+	for _, test := range tests {
+		got, err := parseDecade(test.v)
+		if (err == nil) != test.ok || got != test.want {
+			t.Errorf("parseDecade(%q) = %d, %v, want %d", test.v, got, err, test.want)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		v    string
+		want int
+		ok   bool
+	}{
+		{"120", 120, true},
+		{"120m", 120, true},
+		{"90min", 90, true},
+		{"2h", 120, true},
+		{"2H30M", 150, true},
+		{"1.5h", 90, true},
+		{"h", 0, false},
+		{"two hours", 0, false},
+	}
+	for _, test := range tests {
+		got, err := parseDuration(test.v)
+		if (err == nil) != test.ok || (test.ok && got != test.want) {
+			t.Errorf("parseDuration(%q) = %d, %v, want %d", test.v, got, err, test.want)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		m    int
+		want string
+	}{
+		{45, "45m"},
+		{60, "1h"},
+		{90, "1h30m"},
+		{125, "2h05m"},
+	}
+	for _, test := range tests {
+		if got := formatDuration(test.m); got != test.want {
+			t.Errorf("formatDuration(%d) = %q, want %q", test.m, got, test.want)
+		}
+	}
+}
+
+func TestTakeBudget(t *testing.T) {
+	tests := []struct {
+		args, rest string
+		budget     int
+		ok         bool
+	}{
+		{"2 time=3h", "2", 180, true},
+		{"max:90 grid", "grid", 90, true},
+		{"3 genre:drama", "3 genre:drama", 0, true},
+		{"time=soon", "", 0, false},
+	}
+	for _, test := range tests {
+		rest, b, err := takeBudget(test.args)
+		if (err == nil) != test.ok || rest != test.rest || b != test.budget {
+			t.Errorf("takeBudget(%q) = %q, %d, %v, want %q, %d", test.args, rest, b, err, test.rest,
+				test.budget)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const maxChoices = 8
+
+// normalise lowercases s, strips its accents and replaces punctuation with spaces.
+func normalise(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, ascii(s))
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b []rune) int {
+	D := make([]int, len(b)+1)
+	for j := range D {
+		D[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		p := D[0]
+		D[0] = i
+		for j := 1; j <= len(b); j++ {
+			c := 1
+			if a[i-1] == b[j-1] {
+				c = 0
+			}
+			p, D[j] = D[j], min(min(D[j]+1, D[j-1]+1), p+c)
+		}
+	}
+	return D[len(b)]
+}
+
+// wordDistance returns how many typos apart query word q is from title word t, or -1 if too
+// many. A query word may also be the beginning of a title word.
+func wordDistance(q, t string) int {
+	if strings.HasPrefix(t, q) {
+		return 0
+	}
+	Q, T := []rune(q), []rune(t)
+	tol := len(Q) / 4
+	if len(T) > len(Q) {
+		T = T[:len(Q)]
+	}
+	if d := levenshtein(Q, T); d <= tol {
+		return d
+	}
+	return -1
+}
+
+// matchScore returns how well query matches title, lower being better, or -1 if it does not
+// match at all.
+func matchScore(query, title string) int {
+	q, t := normalise(query), normalise(title)
+	Q, T := strings.Fields(q), strings.Fields(t)
+	if len(Q) == 0 {
+		return -1
+	}
+	if strings.Join(Q, " ") == strings.Join(T, " ") {
+		return 0
+	}
+	if strings.Contains(" "+strings.Join(T, " ")+" ", " "+strings.Join(Q, " ")+" ") {
+		return 1
+	}
+	s := 2
+	for _, w := range Q {
+		best := -1
+		for _, v := range T {
+			if d := wordDistance(w, v); d >= 0 && (best < 0 || d < best) {
+				best = d
+			}
+		}
+		if best < 0 {
+			return -1
+		}
+		s += best
+	}
+	return s
+}
+
+// findMovies returns the indices of movies in L whose title matches query, best matches first.
+// If some title is an exact match, only exact matches are returned.
+func findMovies(query string, L []Entry) []int {
+	type match struct{ i, s int }
+	var M []match
+	for i := range L {
+		if s := matchScore(query, L[i].Title); s >= 0 {
+			M = append(M, match{i, s})
+		}
+	}
+	sort.SliceStable(M, func(i, j int) bool { return M[i].s < M[j].s })
+	var I []int
+	for _, m := range M {
+		if m.s != M[0].s && M[0].s == 0 {
+			break
+		}
+		I = append(I, m.i)
+	}
+	return I
+}
+
+// pickMovie returns the movie of C.movies that arg refers to, either by index or by (partial)
+// title. When a title matches several movies, it asks which one with a button per movie that
+// reruns cmd and returns nil.
+func pickMovie(bot *tgbotapi.BotAPI, u *tgbotapi.Update, C *Chat, cmd, arg string) (int, *Entry) {
+	arg = strings.TrimSpace(arg)
+	if _, err := strconv.Atoi(arg); err == nil || arg == "" {
+		return getMovie(arg, C)
+	}
+	I := findMovies(arg, C.movies)
+	if len(I) == 1 {
+		return I[0], &C.movies[I[0]]
+	}
+	var msg tgbotapi.MessageConfig
+	if len(I) == 0 {
+		msg = tgbotapi.NewMessage(u.Message.Chat.ID, C.T("pick.none", arg))
+	} else {
+		msg = tgbotapi.NewMessage(u.Message.Chat.ID, C.T("pick.which"))
+		var rows [][]tgbotapi.InlineKeyboardButton
+		for k, i := range I {
+			if k == maxChoices {
+				break
+			}
+			m := &C.movies[i]
+			b := tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d. %s (%d)", i, m.Title, m.Year),
+				fmt.Sprintf("%s %d%s", cmd, i, listArg(C)))
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(b))
+		}
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	}
+	msg.ReplyToMessageID = u.Message.MessageID
+	bot.Send(msg)
+	return 0, nil
+}
+
+// pickMovies is like pickMovie, but also accepts a list of indices.
+func pickMovies(bot *tgbotapi.BotAPI, u *tgbotapi.Update, C *Chat, cmd, arg string) []int {
+	if W, err := extractIndices(arg); err == nil {
+		return W
+	}
+	if i, m := pickMovie(bot, u, C, cmd, arg); m != nil {
+		return []int{i}
+	}
+	return nil
+}
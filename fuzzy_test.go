@@ -0,0 +1,51 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchScore(t *testing.T) {
+	tests := []struct {
+		query, title string
+		want         int
+	}{
+		{"The Godfather", "The Godfather", 0},
+		{"the godfather!", "The Godfather", 0},
+		{"Amélie", "Amelie", 0},
+		{"godfather", "The Godfather Part II", 1},
+		{"godfahter", "The Godfather", 4},
+		{"god", "The Godfather", 2},
+		{"alien", "The Godfather", -1},
+		{"", "The Godfather", -1},
+	}
+	for _, test := range tests {
+		if got := matchScore(test.query, test.title); got != test.want {
+			t.Errorf("matchScore(%q, %q) = %d, want %d", test.query, test.title, got, test.want)
+		}
+	}
+}
+
+func TestFindMovies(t *testing.T) {
+	L := []Entry{
+		{Title: "The Godfather Part II"},
+		{Title: "The Godfather"},
+		{Title: "Alien"},
+		{Title: "Aliens"},
+	}
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{"the godfather", []int{1}},
+		{"godfather", []int{0, 1}},
+		{"alien", []int{2}},
+		{"alie", []int{2, 3}},
+		{"jaws", nil},
+	}
+	for _, test := range tests {
+		if got := findMovies(test.query, L); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("findMovies(%q) = %v, want %v", test.query, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"html"
+	"log"
+	"strings"
+)
+
+const CmdLanguage = "language"
+
+const (
+	LangEnglish    = "en"
+	LangPortuguese = "pt"
+)
+
+// languages are the languages we speak, with what we ask IMDb for when looking up titles in each.
+var languages = map[string]struct{ name, accept string }{
+	LangEnglish:    {"English", "en-US,en;q=0.9"},
+	LangPortuguese: {"Português", "pt-BR,pt;q=0.9"},
+}
+
+// language returns the language C speaks.
+func (C *Chat) language() string {
+	if _, e := languages[C.settings.Language]; !e {
+		return LangEnglish
+	}
+	return C.settings.Language
+}
+
+// message returns the message key in C's language, falling back to English when it has no
+// translation.
+func (C *Chat) message(key string) string {
+	s, e := catalog[C.language()][key]
+	if !e {
+		if s, e = catalog[LangEnglish][key]; !e {
+			s = key
+		}
+	}
+	return s
+}
+
+// plural returns the key of the plural form of the message key for n things in C's language.
+func (C *Chat) plural(key string, n int) string {
+	one := n == 1
+	if C.language() == LangPortuguese {
+		one = n == 0 || n == 1
+	}
+	if one {
+		return key + ".one"
+	}
+	return key + ".other"
+}
+
+// format formats s with args, if any.
+func format(s string, args []interface{}) string {
+	if len(args) == 0 {
+		return s
+	}
+	return fmt.Sprintf(s, args...)
+}
+
+// T returns the message key in C's language, formatted with args.
+func (C *Chat) T(key string, args ...interface{}) string {
+	return format(C.message(key), args)
+}
+
+// N returns the plural form of the message key for n things in C's language, formatted with
+// args.
+func (C *Chat) N(key string, n int, args ...interface{}) string {
+	return C.T(C.plural(key, n), args...)
+}
+
+// title returns the title of m as it is known in C's language.
+func (C *Chat) title(m *Entry) string {
+	if t := m.Titles[C.language()]; t != "" {
+		return t
+	}
+	return m.Title
+}
+
+// LocalTitle returns the title of the title with IMDb ID id as it is known in lang, or "" if IMDb
+// doesn't know it by another name.
+func LocalTitle(id, lang string) string {
+	b, err := cache.Fetch("title/"+lang+"/"+id, entryTTL, func() ([]byte, error) {
+		page, err := httpGetLang(imdbPreamble+id+"/", languages[lang].accept)
+		if err != nil {
+			return nil, err
+		}
+		M := ldJSONRegexp.FindSubmatch(page)
+		if M == nil {
+			return nil, fmt.Errorf("no metadata found in page")
+		}
+		// name is the title in the language asked for, while alternateName is the original one.
+		var ld struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(M[1], &ld); err != nil {
+			return nil, err
+		}
+		return []byte(html.UnescapeString(ld.Name)), nil
+	})
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return ""
+	}
+	return string(b)
+}
+
+// localise looks up the titles of the movies of E in C's language.
+func localise(C *Chat, E []Entry) {
+	lang := C.language()
+	if lang == LangEnglish {
+		return
+	}
+	T := make([]string, len(E))
+	resolveAll(len(E), func(i int) *Entry {
+		if E[i].ID != "" && E[i].Titles[lang] == "" {
+			T[i] = LocalTitle(E[i].ID, lang)
+		}
+		return nil
+	})
+	for i, t := range T {
+		if t != "" && t != E[i].Title {
+			if E[i].Titles == nil {
+				E[i].Titles = make(map[string]string)
+			}
+			E[i].Titles[lang] = t
+		}
+	}
+}
+
+// codes lists the languages we speak, for telling members which they can choose.
+func codes() string {
+	return "<code>" + LangEnglish + "</code>, <code>" + LangPortuguese + "</code>"
+}
+
+func Language(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	C := chat(u)
+	var s string
+	if l := strings.ToLower(strings.TrimSpace(u.Message.CommandArguments())); l == "" {
+		s = C.H("language.current", languages[C.language()].name, codes())
+	} else if _, e := languages[l]; !e {
+		s = C.H("language.unknown", escape(l), codes())
+	} else {
+		C.settings.Language = l
+		saveSettings(C)
+		localise(C, C.movies)
+		localise(C, C.watchedMovies)
+		saveMovies(C)
+		s = C.H("language.set")
+	}
+	msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
+	msg.ReplyToMessageID = u.Message.MessageID
+	sendHTML(bot, msg)
+}
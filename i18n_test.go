@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestPlural(t *testing.T) {
+	tests := []struct {
+		lang string
+		n    int
+		want string
+	}{
+		{LangEnglish, 0, "Total: 0 movies watched"},
+		{LangEnglish, 1, "Total: 1 movie watched"},
+		{LangEnglish, 2, "Total: 2 movies watched"},
+		{LangPortuguese, 0, "Total: 0 filme assistido"},
+		{LangPortuguese, 1, "Total: 1 filme assistido"},
+		{LangPortuguese, 2, "Total: 2 filmes assistidos"},
+		{"xx", 2, "Total: 2 movies watched"},
+	}
+	for _, test := range tests {
+		C := &Chat{settings: Settings{Language: test.lang}}
+		if got := C.N("watched.total", test.n, test.n); got != test.want {
+			t.Errorf("N(watched.total, %d) in %q = %q, want %q", test.n, test.lang, got, test.want)
+		}
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	for lang := range languages {
+		for key := range catalog[LangEnglish] {
+			if _, e := catalog[lang][key]; !e {
+				t.Errorf("%q has no %q", lang, key)
+			}
+		}
+		for key := range catalog[lang] {
+			if _, e := catalog[LangEnglish][key]; !e {
+				t.Errorf("%q has %q, which English doesn't", lang, key)
+			}
+		}
+	}
+}
@@ -3,51 +3,138 @@ package main
 import (
 	"bytes"
 	"github.com/nfnt/resize"
+	"golang.org/x/image/webp"
 	"image"
+	"image/gif"
 	"image/jpeg"
-	"io/ioutil"
+	"image/png"
 	"log"
 	"net/http"
 )
 
-func GetImage(url string) (image.Image, int, error) {
-	resp, err := http.Get(url)
+const (
+	maxImageSize   = 5000000
+	maxImageSide   = 1920
+	maxJPEGQuality = 90
+	minJPEGQuality = 30
+)
+
+// Kinds of ImageError.
+const (
+	ErrFetch = iota
+	ErrUnsupported
+	ErrDecode
+	ErrEncode
+	ErrTooLarge
+)
+
+var imageErrors = [...]string{
+	ErrFetch:       "could not download image",
+	ErrUnsupported: "unsupported image format",
+	ErrDecode:      "could not decode image",
+	ErrEncode:      "could not encode image",
+	ErrTooLarge:    "image does not fit in size budget",
+}
+
+// ImageError is an error from some step of the cover processing pipeline.
+type ImageError struct {
+	Kind int
+	Err  error
+}
+
+func (e *ImageError) Error() string {
+	if e.Err == nil {
+		return imageErrors[e.Kind]
+	}
+	return imageErrors[e.Kind] + ": " + e.Err.Error()
+}
+
+func (e *ImageError) Unwrap() error {
+	return e.Err
+}
+
+var decoders = map[string]func(b []byte) (image.Image, error){
+	"image/jpeg": func(b []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(b)) },
+	"image/png":  func(b []byte) (image.Image, error) { return png.Decode(bytes.NewReader(b)) },
+	"image/gif":  func(b []byte) (image.Image, error) { return gif.Decode(bytes.NewReader(b)) },
+	"image/webp": func(b []byte) (image.Image, error) { return webp.Decode(bytes.NewReader(b)) },
+}
+
+// GetImage returns the cover at url of the title whose IMDb ID is id, along with its content
+// type and size in bytes. The content type is sniffed, since IMDb's URLs do not tell.
+func GetImage(id, url string) (image.Image, string, int, error) {
+	b, err := cache.Fetch("poster/"+id, posterTTL, func() ([]byte, error) {
+		return httpGet(url)
+	})
 	if err != nil {
 		log.Printf("[GetImage][HTTPGet] Error: %v", err)
-		return nil, 0, err
+		return nil, "", 0, &ImageError{ErrFetch, err}
 	}
-	defer resp.Body.Close()
-	b, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		log.Printf("[GetImage][ReadBytes] Error: %v", err)
-		return nil, 0, err
+	t := http.DetectContentType(b)
+	decode, ok := decoders[t]
+	if !ok {
+		log.Printf("[GetImage][Sniff] Unsupported content type: %s", t)
+		return nil, t, 0, &ImageError{ErrUnsupported, nil}
 	}
-	img, err := jpeg.Decode(bytes.NewReader(b))
+	img, err := decode(b)
 	if err != nil {
 		log.Printf("[GetImage][Decode] Error: %v", err)
-		return nil, 0, err
+		return nil, t, 0, &ImageError{ErrDecode, err}
 	}
-	return img, len(b), nil
+	return img, t, len(b), nil
 }
 
-func upperBound(x, ub uint) uint {
-	if x > ub {
-		return ub
-	}
-	return x
+// fits returns whether an image can be sent as is.
+func fits(I image.Image, t string, n int) bool {
+	b := I.Bounds()
+	return t == "image/jpeg" && n <= maxImageSize && b.Dx() <= maxImageSide && b.Dy() <= maxImageSide
 }
 
-func Resize(I image.Image) (image.Image, int, []byte, error) {
-	I = resize.Resize(upperBound(uint(I.Bounds().Max.X/2), 1920), 0, I, resize.NearestNeighbor)
-	b, e := Encode(I)
-	return I, len(b), b, e
+// Resize scales I down to fit in maxImageSide and encodes it as a JPEG of at most maxImageSize
+// bytes, lowering the quality as much as needed.
+func Resize(I image.Image) ([]byte, error) {
+	I = resize.Thumbnail(maxImageSide, maxImageSide, I, resize.Lanczos3)
+	for q := maxJPEGQuality; q >= minJPEGQuality; q -= 10 {
+		b, err := Encode(I, q)
+		if err != nil {
+			return nil, &ImageError{ErrEncode, err}
+		}
+		log.Printf("  quality %d: %d/%d", q, len(b), maxImageSize)
+		if len(b) <= maxImageSize {
+			return b, nil
+		}
+	}
+	return nil, &ImageError{ErrTooLarge, nil}
 }
 
-func Encode(I image.Image) ([]byte, error) {
+func Encode(I image.Image, quality int) ([]byte, error) {
 	buf := new(bytes.Buffer)
-	err := jpeg.Encode(buf, I, nil)
+	err := jpeg.Encode(buf, I, &jpeg.Options{Quality: quality})
 	if err != nil {
 		log.Printf("[Encode] Error: %v", err)
 	}
 	return buf.Bytes(), err
 }
+
+// cover returns the bytes to upload as m's cover, or nil if its URL can be shared as is.
+func cover(m *Entry) ([]byte, error) {
+	if b, ok := cache.Get("poster-resized/" + m.ID); ok {
+		log.Printf("Using compressed cover from cache.")
+		return b, nil
+	}
+	img, t, n, err := GetImage(m.ID, m.Cover)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Image has bounds: %v", img.Bounds())
+	if fits(img, t, n) {
+		return nil, nil
+	}
+	log.Printf("Compressing cover...")
+	b, err := Resize(img)
+	if err != nil {
+		return nil, err
+	}
+	cache.Put("poster-resized/"+m.ID, b, posterTTL)
+	return b, nil
+}
@@ -3,26 +3,33 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"golang.org/x/text/transform"
 	"golang.org/x/text/unicode/norm"
-	"io/ioutil"
+	"html"
 	"log"
-	"net/http"
 	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"unicode"
 )
 
 const apiPreamble = "https://sg.media-imdb.com/suggests/"
 
+const (
+	maxConcurrentQueries = 8
+	maxSuggestions       = 3
+)
+
 const (
 	titleKey = "l"
 	idKey    = "id"
 	starsKey = "s"
 	yearKey  = "y"
 	coverKey = "i"
+	typeKey  = "qid"
 )
 
 func isMn(r rune) bool {
@@ -35,9 +42,12 @@ func ascii(s string) string {
 	return r
 }
 
-// convert converts a JSON-P string into an Entry.
-func convert(cnt string) *Entry {
+// suggestions converts a JSON-P string into every Entry with a year and cover it contains.
+func suggestions(cnt string) []Entry {
 	i := strings.Index(cnt, "(")
+	if i < 0 || len(cnt) < i+2 {
+		return nil
+	}
 	cnt = cnt[i+1 : len(cnt)-1]
 
 	var query map[string]interface{}
@@ -51,6 +61,7 @@ func convert(cnt string) *Entry {
 	}
 	log.Printf("Fetching JSON...\n%v", query)
 	entries := query["d"].([]interface{})
+	var S []Entry
 	for i := range entries {
 		e := entries[i].(map[string]interface{})
 		title := e[titleKey].(string)
@@ -67,45 +78,378 @@ func convert(cnt string) *Entry {
 		log.Printf("Cover URL: %s", cover)
 		id := e[idKey].(string)
 		log.Printf("IMDb ID: %s", id)
-		return &Entry{title, year, cover, id, []string{}}
+		t := TypeMovie
+		if q, ok := e[typeKey].(string); ok && titleTypes[q] != "" {
+			t = titleTypes[q]
+		}
+		S = append(S, Entry{Title: title, Year: year, Cover: cover, ID: id, WatchedBy: []string{}, Type: t})
 	}
-	return nil
+	return S
 }
 
-// Retrieve returns an Entry from IMDb's Search Suggestions API.
-func Retrieve(query string) *Entry {
+// Search returns all Entries IMDb's Search Suggestions API suggests for query.
+func Search(query string) []Entry {
 	q := ascii(query)
 	if q == "" {
 		return nil
 	}
 	url := apiPreamble + strings.ToLower(string(q[0])) + "/" + url.PathEscape(query+".json")
-	r, err := http.Get(url)
+	cnt, err := cache.Fetch("search/"+strings.ToLower(q), searchTTL, func() ([]byte, error) {
+		return httpGet(url)
+	})
 	if err != nil {
-		log.Printf("Error: %v", err)
 		return nil
 	}
-	defer r.Body.Close()
-	cnt, err := ioutil.ReadAll(r.Body)
+	S := suggestions(string(cnt))
+	for i := range S {
+		if b, err := json.Marshal(&S[i]); err == nil {
+			cache.Put("entry/"+S[i].ID, b, entryTTL)
+		}
+	}
+	return S
+}
+
+// Retrieve returns an Entry from IMDb's Search Suggestions API.
+func Retrieve(query string) *Entry {
+	S := Search(query)
+	if len(S) == 0 {
+		return nil
+	}
+	return &S[0]
+}
+
+// RetrieveID returns the Entry whose IMDb ID is id.
+func RetrieveID(id string) *Entry {
+	if b, ok := cache.Get("entry/" + id); ok {
+		var e Entry
+		if err := json.Unmarshal(b, &e); err == nil {
+			return &e
+		}
+	}
+	for _, e := range Search(id) {
+		if e.ID == id {
+			return &e
+		}
+	}
+	return nil
+}
+
+// resolveAll calls f for every index in [0, n), at most maxConcurrentQueries at a time, and
+// returns the resulting Entries in order.
+func resolveAll(n int, f func(i int) *Entry) []*Entry {
+	E := make([]*Entry, n)
+	sem := make(chan struct{}, maxConcurrentQueries)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			E[i] = f(i)
+			<-sem
+		}(i)
+	}
+	wg.Wait()
+	return E
+}
+
+// LookupAll looks up the Entry of each query concurrently.
+func LookupAll(queries []string) []*Entry {
+	return resolveAll(len(queries), func(i int) *Entry { return Lookup(queries[i]) })
+}
+
+// Suggest returns a few titles that look like what query meant when it finds nothing. Since
+// IMDb matches on prefixes, typos are usually at the end, so we search for shorter and shorter
+// prefixes of query.
+func Suggest(query string) []string {
+	q := []rune(strings.TrimSpace(query))
+	for k := 1; k <= 3 && len(q)-k >= 2; k++ {
+		S := Search(string(q[:len(q)-k]))
+		if len(S) == 0 {
+			continue
+		}
+		var T []string
+		for i := 0; i < len(S) && i < maxSuggestions; i++ {
+			T = append(T, fmt.Sprintf("%s (%d)", S[i].Title, S[i].Year))
+		}
+		return T
+	}
+	return nil
+}
+
+// details are the parts of an IMDb title page we keep about a title.
+type details struct {
+	Runtime     int
+	Genres      []string
+	Directors   []string
+	Cast        []string
+	Plot        string
+	Certificate string
+	Language    string
+	Rating      float64
+	Similar     []string
+	Series      bool
+	Released    string
+}
+
+const (
+	maxCast    = 5
+	maxSimilar = 12
+)
+
+var (
+	ldJSONRegexp   = regexp.MustCompile(`(?s)<script type="application/ld\+json">(.*?)</script>`)
+	languageRegexp = regexp.MustCompile(`"spokenLanguages":\[\{[^}]*?"text":"([^"]+)"`)
+	durationRegexp = regexp.MustCompile(`^PT(?:(\d+)H)?(?:(\d+)M)?`)
+	titleIDRegexp  = regexp.MustCompile(`"id":"(tt\d{7,})"`)
+)
+
+// similar reads the IMDb IDs of the titles in the "More like this" section of a title page.
+func similar(page []byte, self string) []string {
+	i := bytes.Index(page, []byte(`"moreLikeThisTitles"`))
+	if i < 0 {
+		return nil
+	}
+	page = page[i:]
+	if len(page) > 50000 {
+		page = page[:50000]
+	}
+	var S []string
+	seen := map[string]bool{self: true}
+	for _, M := range titleIDRegexp.FindAllSubmatch(page, -1) {
+		if id := string(M[1]); !seen[id] && len(S) < maxSimilar {
+			seen[id] = true
+			S = append(S, id)
+		}
+	}
+	return S
+}
+
+// people reads the names out of a schema.org Person, or array of Persons.
+func people(raw json.RawMessage) []string {
+	type person struct {
+		Name string `json:"name"`
+	}
+	var P []person
+	if err := json.Unmarshal(raw, &P); err != nil {
+		var p person
+		if err = json.Unmarshal(raw, &p); err != nil {
+			return nil
+		}
+		P = []person{p}
+	}
+	var N []string
+	for _, p := range P {
+		if p.Name != "" {
+			N = append(N, p.Name)
+		}
+	}
+	return N
+}
+
+// parseDetails reads the details of the title with IMDb ID id out of its IMDb page, mostly from
+// its schema.org metadata.
+func parseDetails(id string, page []byte) (*details, error) {
+	M := ldJSONRegexp.FindSubmatch(page)
+	if M == nil {
+		return nil, fmt.Errorf("no metadata found in page")
+	}
+	var ld struct {
+		Type            string          `json:"@type"`
+		Description     string          `json:"description"`
+		ContentRating   string          `json:"contentRating"`
+		Genre           json.RawMessage `json:"genre"`
+		Director        json.RawMessage `json:"director"`
+		Actor           json.RawMessage `json:"actor"`
+		Duration        string          `json:"duration"`
+		DatePublished   string          `json:"datePublished"`
+		AggregateRating struct {
+			RatingValue float64 `json:"ratingValue"`
+		} `json:"aggregateRating"`
+	}
+	if err := json.Unmarshal(M[1], &ld); err != nil {
+		return nil, err
+	}
+	d := &details{
+		Directors:   people(ld.Director),
+		Cast:        people(ld.Actor),
+		Plot:        html.UnescapeString(ld.Description),
+		Certificate: ld.ContentRating,
+		Rating:      ld.AggregateRating.RatingValue,
+		Series:      ld.Type == "TVSeries",
+		Released:    ld.DatePublished,
+	}
+	if err := json.Unmarshal(ld.Genre, &d.Genres); err != nil {
+		var g string
+		if json.Unmarshal(ld.Genre, &g) == nil {
+			d.Genres = []string{g}
+		}
+	}
+	if len(d.Cast) > maxCast {
+		d.Cast = d.Cast[:maxCast]
+	}
+	if D := durationRegexp.FindStringSubmatch(ld.Duration); D != nil {
+		h, _ := strconv.Atoi(D[1])
+		m, _ := strconv.Atoi(D[2])
+		d.Runtime = 60*h + m
+	}
+	if L := languageRegexp.FindSubmatch(page); L != nil {
+		d.Language = string(L[1])
+	}
+	d.Similar = similar(page, id)
+	return d, nil
+}
+
+// Details fills e with the details of its title: runtime, genres, directors, top cast, plot,
+// certificate, original language, release date and IMDb rating.
+func Details(e *Entry) error {
+	b, err := cache.Fetch("details/"+e.ID, entryTTL, func() ([]byte, error) {
+		page, err := httpGet(imdbPreamble + e.ID + "/")
+		if err != nil {
+			return nil, err
+		}
+		d, err := parseDetails(e.ID, page)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(d)
+	})
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return err
+	}
+	var d details
+	if err = json.Unmarshal(b, &d); err != nil {
+		log.Printf("Error: %v", err)
+		return err
+	}
+	d.fill(e)
+	if e.isSeries() {
+		e.Seasons = Episodes(e.ID)
+	}
+	return nil
+}
+
+func (d *details) fill(e *Entry) {
+	e.Runtime, e.Genres, e.Directors, e.Cast = d.Runtime, d.Genres, d.Directors, d.Cast
+	e.Plot, e.Certificate, e.Language = d.Plot, d.Certificate, d.Language
+	e.Similar, e.Released = d.Similar, d.Released
+	if e.Type == "" {
+		e.Type = TypeMovie
+		if d.Series {
+			e.Type = TypeSeries
+		}
+	}
+	if d.Rating > 0 {
+		e.IMDbRating = d.Rating
+	}
+}
+
+// CachedEntry returns the Entry with IMDb ID id and its details without going online, even if
+// what is cached has expired.
+func CachedEntry(id string) (*Entry, bool) {
+	b, ok := cache.Stale("entry/" + id)
+	if !ok {
+		return nil, false
+	}
+	var e Entry
+	if err := json.Unmarshal(b, &e); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, false
+	}
+	if b, ok = cache.Stale("details/" + id); !ok {
+		return nil, false
+	}
+	var d details
+	if err := json.Unmarshal(b, &d); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, false
+	}
+	d.fill(&e)
+	return &e, true
+}
+
+var (
+	seasonRegexp  = regexp.MustCompile(`[?&]season=(\d+)`)
+	episodeMarker = regexp.MustCompile(`S(\d+)\.E(\d+)`)
+)
+
+const maxSeasons = 50
+
+// Episodes returns how many episodes each season of the series with IMDb ID id has.
+func Episodes(id string) []int {
+	b, err := cache.Fetch("episodes/"+id, entryTTL, func() ([]byte, error) {
+		var S []int
+		for s, n := 1, 1; s <= n && s <= maxSeasons; s++ {
+			page, err := httpGet(fmt.Sprintf("%s%s/episodes/?season=%d", imdbPreamble, id, s))
+			if err != nil {
+				return nil, err
+			}
+			for _, M := range seasonRegexp.FindAllSubmatch(page, -1) {
+				if k, _ := strconv.Atoi(string(M[1])); k > n {
+					n = k
+				}
+			}
+			var last int
+			for _, M := range episodeMarker.FindAllSubmatch(page, -1) {
+				k, _ := strconv.Atoi(string(M[1]))
+				e, _ := strconv.Atoi(string(M[2]))
+				if k == s && e > last {
+					last = e
+				}
+			}
+			S = append(S, last)
+		}
+		return json.Marshal(S)
+	})
 	if err != nil {
 		log.Printf("Error: %v", err)
 		return nil
 	}
-	return convert(string(cnt))
+	var S []int
+	if err = json.Unmarshal(b, &S); err != nil {
+		log.Printf("Error: %v", err)
+	}
+	return S
 }
 
-func Rating(url string) float64 {
+// RefreshDetails fetches the details of e again, keeping what is cached if that fails.
+func RefreshDetails(e *Entry) error {
+	cache.Expire("details/" + e.ID)
+	cache.Expire("rating/" + e.ID)
+	cache.Expire("episodes/" + e.ID)
+	return Details(e)
+}
+
+// Lookup returns the Entry IMDb suggests for query, with all its details.
+func Lookup(query string) *Entry {
+	e := Retrieve(query)
+	if e != nil {
+		Details(e)
+	}
+	return e
+}
+
+var ratingRegexp = regexp.MustCompile(`"ratingValue": ?"?(\d+(\.\d+)?)`)
+
+// Rating returns the IMDb rating of the title whose IMDb ID is id, or -1 if it is unknown.
+func Rating(id string) float64 {
 	log.Printf("Fetching rating...")
-	r, err := http.Get(url)
+	b, err := cache.Fetch("rating/"+id, ratingTTL, func() ([]byte, error) {
+		b, err := httpGet(imdbPreamble + id)
+		if err != nil {
+			return nil, err
+		}
+		M := ratingRegexp.FindSubmatch(b)
+		if M == nil {
+			return nil, fmt.Errorf("no rating found for %s", id)
+		}
+		return M[1], nil
+	})
 	if err != nil {
-		log.Printf("Error: %v", err)
 		return -1
 	}
-	defer r.Body.Close()
-	R := regexp.MustCompile(`"ratingValue": "(\d\.\d+)"`)
-	b := new(bytes.Buffer)
-	b.ReadFrom(r.Body)
-	M := R.FindStringSubmatch(b.String())
-	rv, err := strconv.ParseFloat(M[1], 64)
+	rv, err := strconv.ParseFloat(string(b), 64)
 	if err != nil {
 		log.Printf("Error: %v", err)
 		return -1
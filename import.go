@@ -0,0 +1,325 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const CmdImport = "import"
+
+const (
+	maxImportSize = 1000000
+	maxImportRows = 500
+	// maxMissingShown is how many rows we couldn't find an import's summary lists.
+	maxMissingShown = 20
+)
+
+// importRow is a single movie read from an imported file.
+type importRow struct {
+	line  int
+	title string
+	year  int
+	id    string
+}
+
+var (
+	imdbIDRegexp    = regexp.MustCompile(`tt\d{7,}`)
+	titleYearRegexp = regexp.MustCompile(`^(.+?)\s*\((\d{4})\)$`)
+)
+
+// columns finds which columns of an IMDb or Letterboxd CSV header hold the IMDb ID, title and
+// year. It returns a negative title column if the header is not recognised.
+func columns(header []string) (id, title, year int) {
+	id, title, year = -1, -1, -1
+	for i, h := range header {
+		switch strings.ToLower(strings.TrimSpace(h)) {
+		case "const", "imdbid", "imdb id", "tconst":
+			id = i
+		case "title", "name":
+			title = i
+		case "year":
+			year = i
+		}
+	}
+	return
+}
+
+func field(r []string, i int) string {
+	if i < 0 || i >= len(r) {
+		return ""
+	}
+	return strings.TrimSpace(r[i])
+}
+
+// parseImport reads rows from an IMDb list CSV, a Letterboxd watchlist or diary CSV, or a plain
+// text file with one title per line.
+func parseImport(b []byte) []importRow {
+	var R []importRow
+	r := csv.NewReader(bytes.NewReader(b))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	if header, err := r.Read(); err == nil {
+		if id, title, year := columns(header); title >= 0 || id >= 0 {
+			for n := 2; len(R) < maxImportRows; n++ {
+				rec, err := r.Read()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					log.Printf("Error: %v", err)
+					continue
+				}
+				y, _ := strconv.Atoi(field(rec, year))
+				row := importRow{n, field(rec, title), y, imdbIDRegexp.FindString(field(rec, id))}
+				if row.title != "" || row.id != "" {
+					R = append(R, row)
+				}
+			}
+			return R
+		}
+	}
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; s.Scan() && len(R) < maxImportRows; n++ {
+		t := strings.TrimSpace(s.Text())
+		if t == "" {
+			continue
+		}
+		row := importRow{line: n, title: t, id: imdbIDRegexp.FindString(t)}
+		if M := titleYearRegexp.FindStringSubmatch(t); M != nil {
+			row.title = M[1]
+			row.year, _ = strconv.Atoi(M[2])
+		}
+		R = append(R, row)
+	}
+	return R
+}
+
+// resolve finds the Entry an imported row refers to, preferring its IMDb ID when there is one.
+func (r *importRow) resolve() *Entry {
+	if r.id != "" {
+		if e := RetrieveID(r.id); e != nil {
+			return e
+		}
+	}
+	if r.title == "" {
+		return nil
+	}
+	S := Search(r.title)
+	if len(S) == 0 {
+		return nil
+	}
+	if r.year != 0 {
+		for _, e := range S {
+			if e.Year == r.year {
+				return &e
+			}
+		}
+	}
+	return &S[0]
+}
+
+func (r *importRow) String() string {
+	s := r.title
+	if s == "" {
+		s = r.id
+	}
+	if r.year != 0 {
+		s += fmt.Sprintf(" (%d)", r.year)
+	}
+	return s
+}
+
+func download(bot *tgbotapi.BotAPI, d *tgbotapi.Document) ([]byte, error) {
+	url, err := bot.GetFileDirectURL(d.FileID)
+	if err != nil {
+		return nil, err
+	}
+	r, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	return ioutil.ReadAll(io.LimitReader(r.Body, maxImportSize))
+}
+
+// isImport returns whether the message is a document captioned with the import command.
+func isImport(m *tgbotapi.Message) bool {
+	return m.Document != nil && strings.HasPrefix(strings.TrimSpace(m.Caption), "/"+CmdImport)
+}
+
+func Import(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	C := chat(u)
+	var s string
+	d := u.Message.Document
+	if d == nil && u.Message.ReplyToMessage != nil {
+		d = u.Message.ReplyToMessage.Document
+	}
+	if d == nil && strings.TrimSpace(u.Message.CommandArguments()) == "undo" {
+		s = undoImport(C, u)
+		goto send
+	}
+	if d == nil {
+		s = C.H("import.usage")
+		goto send
+	}
+	if d.FileSize > maxImportSize {
+		s = C.H("import.big")
+		goto send
+	}
+	{
+		b, err := download(bot, d)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			s = C.H("import.download")
+			goto send
+		}
+		R := parseImport(b)
+		if len(R) == 0 {
+			s = C.H("import.empty")
+			goto send
+		}
+		var added, present []*Entry
+		var missing []*importRow
+		n := len(C.movies)
+		E := resolveAll(len(R), func(i int) *Entry {
+			e := R[i].resolve()
+			if e != nil {
+				Details(e)
+			}
+			return e
+		})
+		for i, e := range E {
+			if e == nil {
+				missing = append(missing, &R[i])
+			} else if containsMovie(e, C.movies) {
+				present = append(present, e)
+			} else {
+				e.AddedBy = u.Message.From.UserName
+				C.movies = append(C.movies, *e)
+				added = append(added, e)
+			}
+		}
+		localise(C, C.movies[n:])
+		C.lastImport, C.importList = nil, C.list
+		for _, e := range added {
+			C.lastImport = append(C.lastImport, e.ID)
+			record(C, u, ActImport, nil, e)
+		}
+		saveMovies(C)
+		saveImport(C)
+		s = C.HN("import.done", len(added), len(added), len(present))
+		if len(missing) > 0 {
+			s += C.HN("import.missing", len(missing), len(missing))
+			for k, r := range missing {
+				if k == maxMissingShown {
+					s += C.H("import.more", len(missing)-k)
+					break
+				}
+				s += C.H("import.row", r.line, escape(r.String()))
+			}
+		}
+		if len(added) > 0 {
+			s += C.H("import.undo")
+		}
+	}
+send:
+	msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
+	msg.ReplyToMessageID = u.Message.MessageID
+	sendHTML(bot, msg)
+}
+
+// undoImport removes the movies added by the last import that are still in the to-watch list.
+func undoImport(C *Chat, u *tgbotapi.Update) string {
+	if len(C.lastImport) == 0 {
+		return C.H("import.undo.none")
+	}
+	if prev := C.list; C.importList != prev && hasList(C, C.importList) {
+		useList(C, C.importList)
+		defer useList(C, prev)
+	}
+	I := make(map[string]bool, len(C.lastImport))
+	for _, id := range C.lastImport {
+		I[id] = true
+	}
+	var nlist []Entry
+	var n int
+	for _, m := range C.movies {
+		if I[m.ID] {
+			record(C, u, ActRemove, &m, nil)
+			n++
+		} else {
+			nlist = append(nlist, m)
+		}
+	}
+	C.movies = nlist
+	C.lastImport = nil
+	saveMovies(C)
+	saveImport(C)
+	return C.HN("import.undone", n, n)
+}
+
+// importBatch is what import.json holds: the IMDb IDs of the movies the last import added, and
+// the list it added them to.
+type importBatch struct {
+	List string
+	IDs  []string
+}
+
+func saveImport(C *Chat) {
+	f, err := os.Create(C.prefix + "import.json")
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	defer f.Close()
+	b, err := json.Marshal(importBatch{C.importList, C.lastImport})
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	_, err = f.Write(b)
+	if err != nil {
+		log.Printf("Error: %v", err)
+	}
+}
+
+// loadImport reads import.json, which used to hold only the IDs, of movies imported to the
+// default list.
+func loadImport(C *Chat) {
+	f, err := os.Open(C.prefix + "import.json")
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	if len(b) < 5 {
+		return
+	}
+	var i importBatch
+	if err = json.Unmarshal(b, &i); err != nil {
+		if err = json.Unmarshal(b, &i.IDs); err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+	}
+	C.importList, C.lastImport = i.List, i.IDs
+	if C.importList == "" {
+		C.importList = defaultList
+	}
+}
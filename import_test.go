@@ -0,0 +1,44 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseImport(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want []importRow
+	}{
+		{
+			"imdb",
+			"Position,Const,Created,Modified,Description,Title,URL,Title Type,IMDb Rating,Runtime (mins),Year\n" +
+				"1,tt0111161,2020-01-01,,,The Shawshank Redemption,,movie,9.3,142,1994\n" +
+				"2,tt0068646,2020-01-01,,,The Godfather,,movie,9.2,175,1972\n",
+			[]importRow{{2, "The Shawshank Redemption", 1994, "tt0111161"}, {3, "The Godfather", 1972, "tt0068646"}},
+		},
+		{
+			"letterboxd",
+			"Date,Name,Year,Letterboxd URI\n" +
+				"2020-01-01,Parasite,2019,https://boxd.it/abc\n" +
+				"2020-01-02,\"Crouching Tiger, Hidden Dragon\",2000,https://boxd.it/def\n",
+			[]importRow{{2, "Parasite", 2019, ""}, {3, "Crouching Tiger, Hidden Dragon", 2000, ""}},
+		},
+		{
+			"text",
+			"Alien (1979)\n\n  Heat  \nhttps://www.imdb.com/title/tt0133093/\n",
+			[]importRow{
+				{1, "Alien", 1979, ""},
+				{3, "Heat", 0, ""},
+				{4, "https://www.imdb.com/title/tt0133093/", 0, "tt0133093"},
+			},
+		},
+		{"empty", "", nil},
+	}
+	for _, test := range tests {
+		if got := parseImport([]byte(test.file)); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseImport(%s) = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"log"
+	"strings"
+)
+
+const CmdStart = "start"
+
+const (
+	maxInlineResults = 20
+	// addPayload prefixes the IMDb ID in the deep link of an "Add to a list" button.
+	addPayload = "add_"
+)
+
+// inlineLists returns the to-watch lists of every chat the user with Telegram ID id is in.
+func inlineLists(id int) [][]Entry {
+	var L [][]Entry
+	for _, C := range chatMap {
+		if C.isMember(id) {
+			L = append(L, C.movies)
+		}
+	}
+	return L
+}
+
+// card is the text shared for e in other chats, in C's language.
+func card(C *Chat, e *Entry) string {
+	s := fmt.Sprintf("%s (%d)", e.Title, e.Year)
+	if len(e.Genres) != 0 {
+		s += "\n" + strings.Join(e.Genres, ", ")
+	}
+	if len(e.Directors) != 0 {
+		s += C.T("show.directors", strings.Join(e.Directors, ", "))
+	}
+	return s + "\nIMDb: " + imdbPreamble + e.ID
+}
+
+// inlineResult returns a photo result for e, or an article if it has no cover.
+func inlineResult(bot *tgbotapi.BotAPI, C *Chat, e *Entry, description string) interface{} {
+	url := fmt.Sprintf("https://t.me/%s?startgroup=%s%s", bot.Self.UserName, addPayload, e.ID)
+	markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonURL(C.T("inline.add"), url),
+	))
+	title := fmt.Sprintf("%s (%d)", e.Title, e.Year)
+	if e.Cover == "" {
+		r := tgbotapi.NewInlineQueryResultArticle(e.ID, title, card(C, e))
+		r.Description = description
+		r.ReplyMarkup = &markup
+		return r
+	}
+	r := tgbotapi.NewInlineQueryResultPhotoWithThumb(e.ID, e.Cover, e.Cover)
+	r.Title = title
+	r.Description = description
+	r.Caption = card(C, e)
+	r.ReplyMarkup = &markup
+	return r
+}
+
+// Inline answers inline queries (@bot title) with the matching movies from the user's lists,
+// followed by IMDb's suggestions, so they can be shared in any chat.
+func Inline(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	q := u.InlineQuery
+	query := strings.TrimSpace(q.Query)
+	log.Printf("Inline query %q by %s", query, q.From.UserName)
+	// We answer in the language of the user's private chat with us, if they have one.
+	C, e := chatMap[int64(q.From.ID)]
+	if !e {
+		C = &Chat{}
+	}
+	var R []interface{}
+	seen := make(map[string]bool)
+	if query != "" {
+		for _, L := range inlineLists(q.From.ID) {
+			for _, i := range findMovies(query, L) {
+				if e := &L[i]; !seen[e.ID] && len(R) < maxInlineResults {
+					seen[e.ID] = true
+					R = append(R, inlineResult(bot, C, e, C.T("inline.list")))
+				}
+			}
+		}
+		S := Search(query)
+		for i := range S {
+			if e := &S[i]; !seen[e.ID] && len(R) < maxInlineResults {
+				seen[e.ID] = true
+				R = append(R, inlineResult(bot, C, e, C.T("inline.imdb")))
+			}
+		}
+	}
+	_, err := bot.AnswerInlineQuery(tgbotapi.InlineConfig{
+		InlineQueryID: q.ID,
+		Results:       R,
+		CacheTime:     300,
+		IsPersonal:    true,
+	})
+	if err != nil {
+		log.Printf("Error: %v", err)
+	}
+}
+
+// Start greets whoever starts the bot. When started from an "Add to a list" button, it adds the
+// shared movie to this chat's list instead.
+func Start(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	arg := u.Message.CommandArguments()
+	if !strings.HasPrefix(arg, addPayload) {
+		Help(bot, u)
+		return
+	}
+	e := RetrieveID(strings.TrimPrefix(arg, addPayload))
+	if e == nil {
+		msg := tgbotapi.NewMessage(u.Message.Chat.ID, chat(u).T("start.none"))
+		msg.ReplyToMessageID = u.Message.MessageID
+		bot.Send(msg)
+		return
+	}
+	Details(e)
+	if AddEntry(e, u) < 0 {
+		msg := tgbotapi.NewMessage(u.Message.Chat.ID, chat(u).T("add.present"))
+		msg.ReplyToMessageID = u.Message.MessageID
+		bot.Send(msg)
+		return
+	}
+	preview(bot, u, e)
+}
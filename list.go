@@ -19,6 +19,28 @@ type Entry struct {
 	Cover     string
 	ID        string
 	WatchedBy []string
+	Ratings   map[string]int    `json:",omitempty"`
+	AddedBy   string            `json:",omitempty"`
+	Votes     []string          `json:",omitempty"`
+	Tags      []string          `json:",omitempty"`
+	Notes     []Comment         `json:",omitempty"`
+	Comments  []Comment         `json:",omitempty"`
+	Titles    map[string]string `json:",omitempty"`
+
+	Runtime     int      `json:",omitempty"`
+	Genres      []string `json:",omitempty"`
+	Directors   []string `json:",omitempty"`
+	Cast        []string `json:",omitempty"`
+	Plot        string   `json:",omitempty"`
+	Certificate string   `json:",omitempty"`
+	Language    string   `json:",omitempty"`
+	IMDbRating  float64  `json:",omitempty"`
+	Released    string   `json:",omitempty"`
+	Similar     []string `json:",omitempty"`
+
+	Type     string             `json:",omitempty"`
+	Seasons  []int              `json:",omitempty"`
+	Progress map[string]Episode `json:",omitempty"`
 }
 
 const (
@@ -34,15 +56,23 @@ const (
 	CmdDraw    = "draw"
 	CmdSave    = "save"
 	CmdRanking = "ranking"
+	CmdRate    = "rate"
+	CmdRefresh = "refresh"
 )
 
-const maxImageSize = 5000000
-
 const imdbPreamble = "https://www.imdb.com/title/"
 
+const maxCaption = 1024
+
+// containsMovie returns whether c has the title of e, going by IMDb ID when both have one, and by
+// title and year otherwise.
 func containsMovie(e *Entry, c []Entry) bool {
 	for _, m := range c {
-		if e.Title == m.Title && e.Year == m.Year {
+		if e.ID != "" && m.ID != "" {
+			if e.ID == m.ID {
+				return true
+			}
+		} else if e.Title == m.Title && e.Year == m.Year {
 			return true
 		}
 	}
@@ -50,9 +80,18 @@ func containsMovie(e *Entry, c []Entry) bool {
 }
 
 func AddEntry(e *Entry, u *tgbotapi.Update) int {
-	if C := chat(u); !containsMovie(e, C.movies) {
+	return addEntry(chat(u), e, u)
+}
+
+// addEntry adds e to C's list on behalf of whoever sent u, returning its index, or -1 if it was
+// already there.
+func addEntry(C *Chat, e *Entry, u *tgbotapi.Update) int {
+	if !containsMovie(e, C.movies) {
+		e.AddedBy = u.Message.From.UserName
 		C.movies = append(C.movies, *e)
+		localise(C, C.movies[len(C.movies)-1:])
 		saveMovies(C)
+		record(C, u, ActAdd, nil, e)
 		return len(C.movies) - 1
 	} else {
 		return -1
@@ -65,35 +104,131 @@ func Add(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
 	if query == "" {
 		query = C.lastQuery
 	}
-	e := Retrieve(query)
+	if Q := splitTitles(query); len(Q) > 1 {
+		addAll(bot, u, Q)
+		return
+	}
+	e := Lookup(query)
 	if e == nil {
 		return
 	}
 	if AddEntry(e, u) < 0 {
-		msg := tgbotapi.NewMessage(u.Message.Chat.ID, "Movie is already in our to-watch list!")
+		msg := tgbotapi.NewMessage(u.Message.Chat.ID, C.T("add.present"))
 		msg.ReplyToMessageID = u.Message.MessageID
 		bot.Send(msg)
 		return
 	}
 	preview(bot, u, e)
+	offerGroups(bot, u, e)
+}
+
+// splitTitles splits a query with many titles separated by newlines or semicolons.
+func splitTitles(query string) []string {
+	var Q []string
+	for _, q := range strings.FieldsFunc(query, func(r rune) bool { return r == '\n' || r == ';' }) {
+		if q = strings.TrimSpace(q); q != "" {
+			Q = append(Q, q)
+		}
+	}
+	return Q
+}
+
+// addAll adds the top search result of each query to the list and replies with a summary.
+func addAll(bot *tgbotapi.BotAPI, u *tgbotapi.Update, Q []string) {
+	C := chat(u)
+	E := LookupAll(Q)
+	n := len(C.movies)
+	var added, present, missing string
+	for i, e := range E {
+		if e == nil {
+			missing += fmt.Sprintf("  %s", Q[i])
+			if S := Suggest(Q[i]); S != nil {
+				missing += C.T("add.suggest", strings.Join(S, ", "))
+			}
+			missing += "\n"
+		} else if containsMovie(e, C.movies) {
+			present += fmt.Sprintf("  %s (%d)\n", e.Title, e.Year)
+		} else {
+			e.AddedBy = u.Message.From.UserName
+			C.movies = append(C.movies, *e)
+			record(C, u, ActAdd, nil, e)
+			added += fmt.Sprintf("  %d. %s (%d)\n", len(C.movies)-1, e.Title, e.Year)
+		}
+	}
+	localise(C, C.movies[n:])
+	saveMovies(C)
+	var s string
+	if added != "" {
+		s += C.T("add.added") + added
+	}
+	if present != "" {
+		s += C.T("add.already") + present
+	}
+	if missing != "" {
+		s += C.T("add.missing") + missing
+	}
+	msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
+	msg.ReplyToMessageID = u.Message.MessageID
+	bot.Send(msg)
 }
 
 func All(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
 	var s string
+	var I []int
 	C := chat(u)
+	A := C.audience()
+	args, grid := hasOption(u.Message.CommandArguments(), OptGrid)
+	F, _, err := parseFilter(C, args)
+	if err != nil {
+		s = escape(C.explain(err)) + "!"
+		goto send
+	}
+	for i := range C.movies {
+		if F.Match(&C.movies[i]) {
+			I = append(I, i)
+		}
+	}
+	if A != nil {
+		I = forAudience(C, I, A)
+	}
+	if grid {
+		E := make([]Entry, len(I))
+		L := make([]string, len(I))
+		for k, i := range I {
+			E[k] = C.movies[i]
+			L[k] = fmt.Sprintf("%d. %s", i, C.title(&C.movies[i]))
+		}
+		if sendCollage(bot, u, E, L, C.T("all.grid")) {
+			return
+		}
+	}
 	if len(C.movies) == 0 {
-		s = "Movie list is empty! Start adding movies with /add!"
+		s = C.H("all.empty")
 	} else {
-		s = "To-watch movie list:\n"
-		for i, m := range C.movies {
-			s += fmt.Sprintf("  %d. %s (%d)\n", i, m.Title, m.Year)
+		s = C.H("all.header")
+		if C.list != defaultList {
+			s = C.H("all.header.list", escape(C.list))
 		}
-		s += "`/show i` - shows more information on the `i`-th movie."
+		if A != nil {
+			s = C.H("all.header.guests", escape(strings.Join(A, ", @")))
+		}
+		for _, i := range I {
+			m := &C.movies[i]
+			s += fmt.Sprintf("  %d. %s (%d)", i, escape(C.title(m)), m.Year)
+			if m.isSeries() {
+				s += " [" + m.Type + "]"
+			}
+			if v := votesFrom(m, A); A != nil && v > 0 {
+				s += C.HN("all.votes", v, v)
+			}
+			s += "\n"
+		}
+		s += C.H("all.footer")
 	}
+send:
 	msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
 	msg.ReplyToMessageID = u.Message.MessageID
-	msg.ParseMode = tgbotapi.ModeMarkdown
-	bot.Send(msg)
+	sendHTML(bot, msg)
 }
 
 func getMovie(s string, C *Chat) (int, *Entry) {
@@ -108,61 +243,151 @@ func getMovie(s string, C *Chat) (int, *Entry) {
 	return i, &C.movies[i]
 }
 
-func preview(bot *tgbotapi.BotAPI, u *tgbotapi.Update, m *Entry) {
+// caption describes m for its /show card, keeping within Telegram's caption limit by cutting
+// the plot short.
+func caption(C *Chat, m *Entry, offers []Offer) string {
+	s := fmt.Sprintf("%s (%d)", m.Title, m.Year)
+	if m.Runtime > 0 {
+		s += C.T("show.runtime", m.Runtime)
+	}
+	if m.Certificate != "" {
+		s += " - " + m.Certificate
+	}
+	if m.Released != "" && upcoming(m) {
+		s += C.T("show.released", m.Released)
+	}
+	if m.isSeries() {
+		s += " - " + m.Type
+		if n := len(m.Seasons); n > 0 {
+			s += C.N("show.seasons", n, n)
+		}
+	}
+	if len(m.Genres) != 0 {
+		s += "\n" + strings.Join(m.Genres, ", ")
+	}
+	if len(m.Directors) != 0 {
+		s += C.T("show.directors", strings.Join(m.Directors, ", "))
+	}
+	if len(m.Cast) != 0 {
+		s += C.T("show.cast", strings.Join(m.Cast, ", "))
+	}
+	if m.Language != "" {
+		s += C.T("show.language", m.Language)
+	}
+	if len(m.Tags) != 0 {
+		s += C.T("show.tags", hashtags(m.Tags))
+	}
+	r := Rating(m.ID)
+	if r < 0 {
+		r = m.IMDbRating
+	}
+	if r > 0 {
+		s += C.T("show.rating", r)
+	}
+	s += "\nIMDb: " + imdbPreamble + m.ID
+	s += where(C, offers)
+	if len(m.WatchedBy) != 0 {
+		s += C.T("show.watched", len(m.WatchedBy))
+		for _, usr := range m.WatchedBy {
+			s += fmt.Sprintf(" @%s", usr)
+		}
+	}
+	if len(m.Progress) != 0 {
+		s += C.T("show.progress") + m.progress(C)
+	}
+	if len(m.Ratings) != 0 {
+		s += C.T("show.ratings")
+		for usr, r := range m.Ratings {
+			s += fmt.Sprintf(" @%s %d/10", usr, r)
+		}
+	}
+	s += describeNotes(C.T("show.notes"), m.Notes) + describeNotes(C.T("show.discussion"), m.Comments)
+	if m.Plot != "" {
+		p := []rune(m.Plot)
+		if room := maxCaption - len([]rune(s)) - 2; room < len(p) {
+			if room < 4 {
+				return s
+			}
+			p = append(p[:room-3], []rune("...")...)
+		}
+		s += "\n\n" + string(p)
+	}
+	return s
+}
+
+// preview sends m's card, returning the ID of the message sent, or 0 if none was.
+func preview(bot *tgbotapi.BotAPI, u *tgbotapi.Update, m *Entry) int {
 	if m == nil {
-		msg := tgbotapi.NewMessage(u.Message.Chat.ID, "Could not find requested query!")
+		msg := tgbotapi.NewMessage(u.Message.Chat.ID, chat(u).T("add.none"))
 		msg.ReplyToMessageID = u.Message.MessageID
 		bot.Send(msg)
-		return
-	}
-	var cbytes []byte
-	var icover tgbotapi.FileBytes
-	scover := m.Cover
-	byFile := true
-	img, n, err := GetImage(m.Cover)
-	if err != nil || (n < maxImageSize && img.Bounds().Max.X <= 1920) {
-		byFile = false
-		goto send
+		return 0
 	}
-	log.Printf("Compressing cover...")
-	for n > maxImageSize || img.Bounds().Max.X > 1920 {
-		log.Printf("  %d/%d", n, maxImageSize)
-		img, n, cbytes, err = Resize(img)
-		log.Printf("  -- %d/%d", n, maxImageSize)
+	C := chat(u)
+	text := caption(C, m, offersFor(C, m))
+	if fid, ok := cache.Get("fileid/" + m.ID); ok {
+		log.Printf("Sending cover by file ID.")
+		msg := tgbotapi.NewPhotoShare(u.Message.Chat.ID, string(fid))
+		msg.Caption = text
+		msg.ReplyToMessageID = u.Message.MessageID
+		sent, err := bot.Send(msg)
+		if err == nil {
+			return sent.MessageID
+		}
+		log.Printf("Error: %v", err)
+		log.Printf("Telegram rejected the cover's file ID, sending it again.")
 	}
+	b, err := cover(m)
 	if err != nil {
 		log.Printf("Error: %v", err)
-		byFile = false
-		goto send
+		return textCard(bot, u, text)
 	}
-	icover = tgbotapi.FileBytes{"cover", cbytes}
-send:
-	log.Printf("Image has bounds: %v", img.Bounds())
 	var msg tgbotapi.PhotoConfig
-	if byFile {
+	if b != nil {
 		log.Printf("Sending cover by file.")
-		msg = tgbotapi.NewPhotoUpload(u.Message.Chat.ID, icover)
+		msg = tgbotapi.NewPhotoUpload(u.Message.Chat.ID, tgbotapi.FileBytes{Name: "cover", Bytes: b})
 	} else {
 		log.Printf("Sending cover by URL.")
-		msg = tgbotapi.NewPhotoShare(u.Message.Chat.ID, scover)
+		msg = tgbotapi.NewPhotoShare(u.Message.Chat.ID, m.Cover)
 	}
-	turl := imdbPreamble + m.ID
-	msg.Caption = fmt.Sprintf("%s (%d)\nRating: %.1f/10.0\nIMDb: %s", m.Title, m.Year, Rating(turl), turl)
-	if len(m.WatchedBy) != 0 {
-		msg.Caption += fmt.Sprintf("\nWatched by (%d):", len(m.WatchedBy))
-		for _, usr := range m.WatchedBy {
-			msg.Caption += fmt.Sprintf(" @%s", usr)
-		}
+	msg.Caption = text
+	msg.ReplyToMessageID = u.Message.MessageID
+	sent, err := bot.Send(msg)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return textCard(bot, u, text)
 	}
+	rememberPhoto(m.ID, &sent)
+	return sent.MessageID
+}
+
+// textCard sends a movie's caption on its own, for when its cover cannot be sent.
+func textCard(bot *tgbotapi.BotAPI, u *tgbotapi.Update, caption string) int {
+	msg := tgbotapi.NewMessage(u.Message.Chat.ID, caption)
 	msg.ReplyToMessageID = u.Message.MessageID
-	bot.Send(msg)
+	sent, err := bot.Send(msg)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return 0
+	}
+	return sent.MessageID
+}
+
+// rememberPhoto saves the file ID Telegram gave to the largest size of the photo in sent, so the
+// same cover can be sent again to any chat without being downloaded or uploaded.
+func rememberPhoto(id string, sent *tgbotapi.Message) {
+	if sent.Photo == nil || len(*sent.Photo) == 0 {
+		return
+	}
+	P := *sent.Photo
+	cache.Put("fileid/"+id, []byte(P[len(P)-1].FileID), 0)
 }
 
 func Query(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
 	q := u.Message.CommandArguments()
 	C := chat(u)
 	C.lastQuery = q
-	preview(bot, u, Retrieve(q))
+	preview(bot, u, Lookup(q))
 }
 
 func Show(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
@@ -170,26 +395,29 @@ func Show(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
 	if len(C.movies) == 0 {
 		return
 	}
-	_, m := getMovie(u.Message.CommandArguments(), C)
+	_, m := pickMovie(bot, u, C, CmdShow, u.Message.CommandArguments())
 	if m == nil {
 		return
 	}
-	preview(bot, u, m)
+	if id := preview(bot, u, m); id != 0 {
+		rememberCard(C, id, m.ID)
+	}
 }
 
 func Remove(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
 	C := chat(u)
-	i, m := getMovie(u.Message.CommandArguments(), C)
+	i, m := pickMovie(bot, u, C, CmdRemove, u.Message.CommandArguments())
 	if m == nil {
 		return
 	}
 	r := C.movies[i]
 	C.movies = append(C.movies[:i], C.movies[i+1:]...)
-	s := fmt.Sprintf("Removing %s (%d) from movie list...", r.Title, r.Year)
+	s := C.T("remove.done", r.Title, r.Year)
 	msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
 	msg.ReplyToMessageID = u.Message.MessageID
 	bot.Send(msg)
 	saveMovies(C)
+	record(C, u, ActRemove, &r, nil)
 }
 
 func extractIndices(whole string) ([]int, error) {
@@ -211,53 +439,92 @@ func checkWatched(u *tgbotapi.Update) string {
 	var nlist []Entry
 	C := chat(u)
 	C.undoMovies = []Entry{}
+	C.undoList = C.list
 	for _, m := range C.movies {
 		if len(m.WatchedBy) >= len(C.allUsers) {
-			msg += fmt.Sprintf("  %s (%d)\n", m.Title, m.Year)
+			msg += fmt.Sprintf("  %s (%d)\n", escape(C.title(&m)), m.Year)
 			C.undoMovies = append(C.undoMovies, m)
 			C.watchedMovies = append(C.watchedMovies, m)
+			record(C, u, ActArchive, &m, nil)
 		} else {
 			nlist = append(nlist, m)
 		}
 	}
 	C.movies = nlist
 	if msg != "" {
-		msg = fmt.Sprintf("I've removed the following movies because everyone has watched them!\n%s"+
-			"To undo these changes, tell me to `/restore`.", msg)
+		msg = C.HN("archived", len(C.undoMovies), msg)
 	}
 	return msg
 }
 
 func Watch(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
 	usr := u.Message.From.UserName
-	W, err := extractIndices(u.Message.CommandArguments())
-	if err != nil {
-		return
+	C := chat(u)
+	args, ep := takeEpisode(u.Message.CommandArguments())
+	cmd := CmdWatch
+	if ep != nil {
+		cmd += " " + ep.String()
 	}
+	W := pickMovies(bot, u, C, cmd, args)
 	var change bool
-	C := chat(u)
+	var s string
 	for _, w := range W {
-		if w >= 0 && w < len(C.movies) {
-			var in bool
-			L := C.movies[w].WatchedBy
-			for _, name := range L {
-				if name == usr {
-					in = true
-					break
+		if w < 0 || w >= len(C.movies) {
+			continue
+		}
+		m := &C.movies[w]
+		before := snapshot(m)
+		if ep != nil && !m.isSeries() {
+			s += C.T("watch.film", m.Title, m.Year)
+			continue
+		}
+		if last, known := m.lastEpisode(); m.isSeries() && (ep != nil || known) {
+			e := last
+			if ep != nil {
+				if !m.valid(*ep) {
+					s += C.T("watch.episode", m.Title, m.Year, ep)
+					continue
 				}
+				e = *ep
+			}
+			if m.Progress == nil {
+				m.Progress = make(map[string]Episode)
+			}
+			m.Progress[usr] = e
+			change = true
+			record(C, u, ActEpisode, before, m)
+			if n, more := m.next(e); more {
+				s += C.T("watch.next", m.Title, m.Year, n)
+			} else {
+				s += C.T("watch.finished", m.Title, m.Year)
+			}
+			j := indexOf(usr, m.WatchedBy)
+			if m.finished(usr) == (j >= 0) {
+				continue
 			}
-			if !in {
-				C.movies[w].WatchedBy = append(C.movies[w].WatchedBy, usr)
-				change = true
+			before = snapshot(m)
+			if j >= 0 {
+				m.WatchedBy = append(m.WatchedBy[:j], m.WatchedBy[j+1:]...)
+				record(C, u, ActUnwatch, before, m)
+				continue
 			}
+		} else if inList(usr, m.WatchedBy) {
+			continue
 		}
+		m.WatchedBy = append(m.WatchedBy, usr)
+		change = true
+		record(C, u, ActWatch, before, m)
+	}
+	if s != "" {
+		msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
+		msg.ReplyToMessageID = u.Message.MessageID
+		bot.Send(msg)
 	}
 	if change {
 		if c := checkWatched(u); c != "" {
 			msg := tgbotapi.NewMessage(u.Message.Chat.ID, c)
 			msg.ReplyToMessageID = u.Message.MessageID
-			msg.ParseMode = tgbotapi.ModeMarkdown
-			bot.Send(msg)
+			sendHTML(bot, msg)
 		}
 	}
 	saveMovies(C)
@@ -265,35 +532,78 @@ func Watch(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
 
 func Unwatch(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
 	usr := u.Message.From.UserName
-	W, err := extractIndices(u.Message.CommandArguments())
-	if err != nil {
-		return
-	}
 	C := chat(u)
+	W := pickMovies(bot, u, C, CmdUnwatch, u.Message.CommandArguments())
 	for _, w := range W {
 		if w >= 0 && w < len(C.movies) {
 			m := &C.movies[w]
-			i := -1
-			for j, watcher := range m.WatchedBy {
-				if watcher == usr {
-					i = j
-					break
-				}
-			}
-			if i >= 0 {
+			before := snapshot(m)
+			_, started := m.Progress[usr]
+			delete(m.Progress, usr)
+			if i := indexOf(usr, m.WatchedBy); i >= 0 {
 				m.WatchedBy = append(m.WatchedBy[:i], m.WatchedBy[i+1:]...)
+			} else if !started {
+				continue
 			}
+			record(C, u, ActUnwatch, before, m)
+		}
+	}
+	saveMovies(C)
+}
+
+func Rate(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	C := chat(u)
+	args := strings.Fields(u.Message.CommandArguments())
+	L := &C.movies
+	if len(args) > 0 && args[0] == CmdWatched {
+		L = &C.watchedMovies
+		args = args[1:]
+	}
+	var s string
+	var i, r int
+	var err error
+	if len(args) != 2 {
+		goto usage
+	}
+	if i, err = strconv.Atoi(args[0]); err != nil || i < 0 || i >= len(*L) {
+		goto usage
+	}
+	if r, err = strconv.Atoi(args[1]); err != nil || r < 1 || r > 10 {
+		goto usage
+	}
+	{
+		m := &(*L)[i]
+		before := snapshot(m)
+		if m.Ratings == nil {
+			m.Ratings = make(map[string]int)
 		}
+		m.Ratings[u.Message.From.UserName] = r
+		saveMovies(C)
+		record(C, u, ActRate, before, m)
+		s = C.H("rate.done", escape(m.Title), m.Year, r)
 	}
+	goto send
+usage:
+	s = C.H("rate.usage")
+send:
+	msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
+	msg.ReplyToMessageID = u.Message.MessageID
+	sendHTML(bot, msg)
 }
 
 func Restore(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
 	if C := chat(u); C.undoMovies != nil {
+		if prev := C.list; C.undoList != prev && hasList(C, C.undoList) {
+			useList(C, C.undoList)
+			defer useList(C, prev)
+		}
 		for _, m := range C.undoMovies {
+			before := snapshot(&m)
 			m.WatchedBy = []string{}
 			C.movies = append(C.movies, m)
+			record(C, u, ActRestore, before, &m)
 		}
-		C.watchedMovies = append(C.watchedMovies[:len(C.watchedMovies)-len(C.undoMovies)])
+		C.watchedMovies = C.watchedMovies[:len(C.watchedMovies)-len(C.undoMovies)]
 		C.undoMovies = nil
 		saveMovies(C)
 	}
@@ -306,95 +616,184 @@ func Watched(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
 		uname := ToUsername(u)
 		_, e := C.User(uname)
 		if !e {
-			s = fmt.Sprintf("I don't know who %s is!", uname)
+			s = C.H("watched.unknown", escape(uname))
 			goto send
 		}
-		s = fmt.Sprintf("Movies watched by %s still in the to-watch list:\n", uname)
+		s = C.H("watched.pending", escape(uname))
 		var c int
 		for i, m := range C.movies {
 			for _, w := range m.WatchedBy {
 				if strings.ToLower(w) == uname {
-					s += fmt.Sprintf("  %d. %s (%d) {%d}\n", c, m.Title, m.Year, i)
+					s += fmt.Sprintf("  %d. %s (%d) {%d}\n", c, escape(C.title(&m)), m.Year, i)
 					c++
 					break
 				}
 			}
 		}
-		s += fmt.Sprintf("Movies watched by %s in the watched list:\n", uname)
+		s += C.H("watched.archived", escape(uname))
 		var d int
 		for _, m := range C.watchedMovies {
 			for _, w := range m.WatchedBy {
 				if strings.ToLower(w) == uname {
-					s += fmt.Sprintf("  %d. %s (%d)\n", d, m.Title, m.Year)
+					s += fmt.Sprintf("  %d. %s (%d)\n", d, escape(C.title(&m)), m.Year)
 					d++
 					break
 				}
 			}
 		}
-		s += fmt.Sprintf("Total movies watched: %d", c+d)
+		s += C.HN("watched.total", c+d, c+d)
 		goto send
 	}
 	if len(C.watchedMovies) == 0 {
-		s = "You have not watched any movies yet! :("
+		s = C.H("watched.empty")
 	} else {
-		s = "Watched movie list:\n"
+		s = C.H("watched.header")
 		for i, m := range C.watchedMovies {
-			s += fmt.Sprintf("  %d. %s (%d)\n", i, m.Title, m.Year)
+			s += fmt.Sprintf("  %d. %s (%d)\n", i, escape(C.title(&m)), m.Year)
 		}
 	}
 send:
 	msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
 	msg.ReplyToMessageID = u.Message.MessageID
-	msg.ParseMode = tgbotapi.ModeMarkdown
-	bot.Send(msg)
+	sendHTML(bot, msg)
 }
 
 func Draw(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
-	args := u.Message.CommandArguments()
-	var n int
+	C := chat(u)
+	args, grid := hasOption(u.Message.CommandArguments(), OptGrid)
+	args, again := hasOption(args, OptAgain)
+	args, budget, err := takeBudget(args)
+	var F Filter
+	if err == nil {
+		F, args, err = parseFilter(C, args)
+	}
+	if err != nil {
+		msg := tgbotapi.NewMessage(u.Message.Chat.ID, C.explain(err)+"!")
+		msg.ReplyToMessageID = u.Message.MessageID
+		bot.Send(msg)
+		return
+	}
+	n := 1
+	if budget > 0 {
+		n = len(C.movies)
+	}
 	if args != "" {
 		L, err := extractIndices(args)
 		if err != nil {
 			log.Printf("Error: %v", err)
 			return
 		}
-		n = L[0]
-	} else {
-		n = 1
+		if n = L[0]; n < 1 {
+			msg := tgbotapi.NewMessage(u.Message.Chat.ID, C.T("draw.positive"))
+			msg.ReplyToMessageID = u.Message.MessageID
+			bot.Send(msg)
+			return
+		}
 	}
-	C := chat(u)
-	if n > len(C.movies) {
-		n = len(C.movies)
+	candidates := func(fresh bool) []int {
+		var K []int
+		for i := range C.movies {
+			m := &C.movies[i]
+			if F.Match(m) && (budget == 0 || (m.Runtime > 0 && m.Runtime <= budget)) &&
+				!(fresh && drawnRecently(C, m.ID)) {
+				K = append(K, i)
+			}
+		}
+		return K
+	}
+	K := candidates(!again)
+	if len(K) == 0 && !again {
+		K = candidates(false)
 	}
+	if A := C.audience(); A != nil {
+		K = forAudience(C, K, A)
+	}
+	rand.Shuffle(len(K), func(i, j int) { K[i], K[j] = K[j], K[i] })
 	type entryIndex struct {
 		e Entry
 		i int
 	}
 	var M []entryIndex
-	G := make(map[int]bool)
-	for i := 0; i < n; i++ {
-		for {
-			k := rand.Intn(len(C.movies))
-			if _, e := G[k]; !e {
-				G[k] = true
-				M = append(M, entryIndex{C.movies[k], k})
-				break
-			}
+	var total int
+	for _, k := range K {
+		if len(M) >= n {
+			break
+		}
+		if budget > 0 && total+C.movies[k].Runtime > budget {
+			continue
 		}
+		total += C.movies[k].Runtime
+		M = append(M, entryIndex{C.movies[k], k})
 	}
 	log.Println(M)
-	if M != nil {
-		s := "I've chosen these movies for you to watch. Have fun! :)\n"
-		for i, m := range M {
-			s += fmt.Sprintf("  %d. %s (%d) {%d}\n", i, m.e.Title, m.e.Year, m.i)
-		}
-		s += "You can find out more about each movie with `/show i` where `i` is the number in " +
-			"{curly braces}. Don't forget to `/watch i` when you're finished watching movie `i`!"
-		msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
+	if M == nil {
+		msg := tgbotapi.NewMessage(u.Message.Chat.ID, C.T("draw.none"))
 		msg.ReplyToMessageID = u.Message.MessageID
-		msg.ParseMode = tgbotapi.ModeMarkdown
 		bot.Send(msg)
+		return
+	}
+	D := make([]Entry, len(M))
+	for i, m := range M {
+		D[i] = m.e
+	}
+	markDrawn(C, D)
+	if grid {
+		L := make([]string, len(M))
+		for i, m := range M {
+			L[i] = fmt.Sprintf("{%d} %s", m.i, C.title(&m.e))
+		}
+		if sendCollage(bot, u, D, L, C.N("draw.chosen", len(M))) {
+			return
+		}
+	}
+	s := C.HN("draw.chosen", len(M)) + "\n"
+	for i, m := range M {
+		s += fmt.Sprintf("  %d. %s (%d) {%d}", i, escape(C.title(&m.e)), m.e.Year, m.i)
+		if budget > 0 {
+			s += " " + formatDuration(m.e.Runtime)
+		}
+		s += "\n"
+	}
+	if budget > 0 {
+		s += C.H("draw.budget", formatDuration(total), formatDuration(budget))
 	}
+	s += C.H("draw.footer")
+	msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
+	msg.ReplyToMessageID = u.Message.MessageID
+	sendHTML(bot, msg)
+}
+
+func Refresh(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	C := chat(u)
+	var L []*Entry
+	if strings.TrimSpace(u.Message.CommandArguments()) == "" {
+		for i := range C.movies {
+			L = append(L, &C.movies[i])
+		}
+		for i := range C.watchedMovies {
+			L = append(L, &C.watchedMovies[i])
+		}
+	} else if _, m := pickMovie(bot, u, C, CmdRefresh, u.Message.CommandArguments()); m != nil {
+		L = []*Entry{m}
+	} else {
+		return
+	}
+	E := resolveAll(len(L), func(i int) *Entry {
+		if RefreshDetails(L[i]) != nil {
+			return nil
+		}
+		return L[i]
+	})
+	var n int
+	for _, e := range E {
+		if e != nil {
+			n++
+		}
+	}
+	saveMovies(C)
+	msg := tgbotapi.NewMessage(u.Message.Chat.ID, C.N("refresh.done", len(L), n, len(L)))
+	msg.ReplyToMessageID = u.Message.MessageID
+	bot.Send(msg)
 }
 
 func Save(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
@@ -422,9 +821,9 @@ func saveList(filename string, list []Entry) {
 }
 
 func saveMovies(C *Chat) {
-	saveList(C.prefix+"movies.json", C.movies)
+	saveList(listPath(C, C.list), C.movies)
 	saveList(C.prefix+"watched.json", C.watchedMovies)
-	saveList(C.prefix+"undo.json", C.undoMovies)
+	saveUndo(C)
 }
 
 func loadList(filename string, list *[]Entry) {
@@ -455,9 +854,63 @@ func loadList(filename string, list *[]Entry) {
 
 func loadMovies(u *tgbotapi.Update) {
 	C := chat(u)
-	loadList(C.prefix+"movies.json", &C.movies)
+	loadList(listPath(C, C.list), &C.movies)
 	loadList(C.prefix+"watched.json", &C.watchedMovies)
-	loadList(C.prefix+"undo.json", &C.undoMovies)
+	loadUndo(C)
+}
+
+// undoBatch is what undo.json holds: the movies checkWatched last archived, and the list they
+// were taken from.
+type undoBatch struct {
+	List   string
+	Movies []Entry
+}
+
+func saveUndo(C *Chat) {
+	f, err := os.Create(C.prefix + "undo.json")
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	defer f.Close()
+	b, err := json.Marshal(undoBatch{C.undoList, C.undoMovies})
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	_, err = f.Write(b)
+	if err != nil {
+		log.Printf("Error: %v", err)
+	}
+}
+
+// loadUndo reads undo.json, which used to hold only the movies, taken from the default list.
+func loadUndo(C *Chat) {
+	f, err := os.Open(C.prefix + "undo.json")
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	if len(b) < 5 {
+		return
+	}
+	var u undoBatch
+	if err = json.Unmarshal(b, &u); err != nil {
+		if err = json.Unmarshal(b, &u.Movies); err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+	}
+	C.undoList, C.undoMovies = u.List, u.Movies
+	if C.undoList == "" {
+		C.undoList = defaultList
+	}
 }
 
 func Ranking(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
@@ -494,7 +947,7 @@ func Ranking(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
 	sort.Slice(S, func(i, j int) bool {
 		return S[i].w > S[j].w
 	})
-	t := "Ranking of number of watched movies:\n"
+	t := C.T("ranking.header")
 	for i, s := range S {
 		t += fmt.Sprintf("  %d. %s (%d)\n", i+1, s.u.UserName, s.w)
 	}
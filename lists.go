@@ -0,0 +1,223 @@
+package main
+
+import (
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"io/ioutil"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const CmdList = "list"
+
+// defaultList is the list every chat starts with. It is stored as movies.json, while the others
+// are stored in the lists directory.
+const defaultList = "main"
+
+var (
+	listNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+	listArgRegexp  = regexp.MustCompile(`(?i)(^|\s+)list[:=](\S+)`)
+)
+
+// listPath returns the file the list called name is stored in.
+func listPath(C *Chat, name string) string {
+	if name == defaultList {
+		return C.prefix + "movies.json"
+	}
+	return C.prefix + "lists/" + name + ".json"
+}
+
+// listNames returns the names of C's lists, the default one first.
+func listNames(C *Chat) []string {
+	L := []string{defaultList}
+	D, err := ioutil.ReadDir(C.prefix + "lists")
+	if err != nil {
+		return L
+	}
+	for _, d := range D {
+		if name := strings.TrimSuffix(d.Name(), ".json"); name != d.Name() {
+			L = append(L, name)
+		}
+	}
+	return L
+}
+
+func hasList(C *Chat, name string) bool {
+	return inList(name, listNames(C))
+}
+
+// listArg returns the argument that makes a command act on the list in use, for buttons that
+// may be pressed after the chat switched lists. It is empty if the chat has a single list.
+func listArg(C *Chat) string {
+	if len(listNames(C)) == 1 {
+		return ""
+	}
+	return " list:" + C.list
+}
+
+// useList makes commands act on the list called name, saving the one in use first.
+func useList(C *Chat, name string) {
+	if name == C.list {
+		return
+	}
+	saveList(listPath(C, C.list), C.movies)
+	C.list = name
+	C.movies = nil
+	loadList(listPath(C, name), &C.movies)
+}
+
+// forList makes the command in u act on the list given by its list:name argument, if any,
+// removing the argument. It returns a function that switches back to the list in use, or false
+// if there is no such list.
+func forList(bot *tgbotapi.BotAPI, u *tgbotapi.Update) (func(), bool) {
+	M := listArgRegexp.FindStringSubmatchIndex(u.Message.Text)
+	if M == nil {
+		return func() {}, true
+	}
+	name := strings.ToLower(u.Message.Text[M[4]:M[5]])
+	u.Message.Text = u.Message.Text[:M[0]] + u.Message.Text[M[1]:]
+	C := chat(u)
+	if !hasList(C, name) {
+		msg := tgbotapi.NewMessage(u.Message.Chat.ID, C.H("list.unknown", escape(name)))
+		msg.ReplyToMessageID = u.Message.MessageID
+		sendHTML(bot, msg)
+		return nil, false
+	}
+	prev := C.list
+	useList(C, name)
+	return func() { useList(C, prev) }, true
+}
+
+// moveMovies moves the movies of the list in use with indices I to the list called name.
+func moveMovies(C *Chat, u *tgbotapi.Update, I []int, name string) string {
+	var L []Entry
+	loadList(listPath(C, name), &L)
+	sort.Sort(sort.Reverse(sort.IntSlice(I)))
+	var s string
+	for k, i := range I {
+		if i < 0 || i >= len(C.movies) || (k > 0 && I[k-1] == i) {
+			continue
+		}
+		m := C.movies[i]
+		if containsMovie(&m, L) {
+			s += C.H("list.present", escape(m.Title), m.Year, escape(name))
+			continue
+		}
+		L = append(L, m)
+		C.movies = append(C.movies[:i], C.movies[i+1:]...)
+		record(C, u, ActMove, &m, nil)
+		s += C.H("list.moved", escape(m.Title), m.Year, escape(name))
+	}
+	saveList(listPath(C, name), L)
+	saveMovies(C)
+	return s
+}
+
+func List(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	C := chat(u)
+	var s string
+	args := strings.Fields(strings.ToLower(u.Message.CommandArguments()))
+	if len(args) == 0 {
+		s = C.H("list.header")
+		for _, name := range listNames(C) {
+			L := C.movies
+			if name != C.list {
+				L = nil
+				loadList(listPath(C, name), &L)
+			}
+			s += C.HN("list.item", len(L), escape(name), len(L))
+			if name == C.list {
+				s += C.H("list.current")
+			}
+			s += "\n"
+		}
+		s += C.H("list.footer")
+		goto send
+	}
+	switch {
+	case args[0] == "create" && len(args) == 2:
+		name := args[1]
+		if !listNameRegexp.MatchString(name) {
+			s = C.H("list.name")
+		} else if hasList(C, name) {
+			s = C.H("list.exists", escape(name))
+		} else {
+			if err := os.MkdirAll(C.prefix+"lists", os.ModePerm); err != nil {
+				log.Printf("Error: %v", err)
+			}
+			saveList(listPath(C, name), []Entry{})
+			s = C.H("list.created", escape(name))
+		}
+	case args[0] == "rename" && len(args) == 3:
+		old, name := args[1], args[2]
+		if old == defaultList || !hasList(C, old) {
+			s = C.H("list.rename.refused", escape(old))
+		} else if !listNameRegexp.MatchString(name) {
+			s = C.H("list.name")
+		} else if hasList(C, name) {
+			s = C.H("list.exists", escape(name))
+		} else if err := os.Rename(listPath(C, old), listPath(C, name)); err != nil {
+			log.Printf("Error: %v", err)
+			s = C.H("list.rename.failed", escape(old))
+		} else {
+			if C.list == old {
+				C.list = name
+			}
+			if C.settings.List == old {
+				C.settings.List = name
+				saveSettings(C)
+			}
+			s = C.H("list.renamed", escape(old), escape(name))
+		}
+	case args[0] == "delete" && len(args) == 2:
+		name := args[1]
+		var L []Entry
+		loadList(listPath(C, name), &L)
+		if name == defaultList || !hasList(C, name) {
+			s = C.H("list.delete.refused", escape(name))
+		} else if len(L) > 0 {
+			s = C.HN("list.delete.full", len(L), escape(name), len(L))
+		} else {
+			if C.list == name {
+				useList(C, defaultList)
+			}
+			if C.settings.List == name {
+				C.settings.List = ""
+				saveSettings(C)
+			}
+			if err := os.Remove(listPath(C, name)); err != nil {
+				log.Printf("Error: %v", err)
+			}
+			s = C.H("list.deleted", escape(name))
+		}
+	case args[0] == "switch" && len(args) == 2:
+		name := args[1]
+		if !hasList(C, name) {
+			s = C.H("list.missing", escape(name))
+		} else {
+			useList(C, name)
+			C.settings.List = name
+			saveSettings(C)
+			s = C.HN("list.switched", len(C.movies), escape(name), len(C.movies))
+		}
+	case args[0] == "move" && len(args) >= 3:
+		name := args[1]
+		if !hasList(C, name) || name == C.list {
+			s = C.H("list.move.refused", escape(name))
+		} else {
+			I := pickMovies(bot, u, C, CmdList+" move "+name, strings.Join(args[2:], " "))
+			if len(I) == 0 {
+				return
+			}
+			s = moveMovies(C, u, I, name)
+		}
+	default:
+		s = C.H("list.usage")
+	}
+send:
+	msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
+	msg.ReplyToMessageID = u.Message.MessageID
+	sendHTML(bot, msg)
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"runtime/debug"
+	"time"
 )
 
 const CmdHelp = "help"
@@ -14,33 +15,33 @@ const MaxGCIterations = 10
 
 var gcIterations = 0
 
+// helpSections are the messages of the catalog Help sends, one at a time, since all of them
+// together are longer than Telegram allows a message to be.
+var helpSections = []string{"help.list", "help.draw", "help.more"}
+
 func Help(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
-	const s = "List of commands:\n" +
-		"  `/all`: prints current movie list\n" +
-		"  `/show i`: prints more info on the `i`-th item of list\n" +
-		"  `/remove i`: removes `i`-th item from list\n" +
-		"  `/add title`: adds top search result of `title` to list\n" +
-		"  `/query title`: queries IMDb for `title`\n" +
-		"  `/watch i1 i2 ...`: mark all `ij` instances as `watched` by you\n" +
-		"  `/unwatch i1 i2 ...`: mark all `ij` instances as `unwatched` by you\n" +
-		"  `/restore`: restore last automatically removed items of movie list\n" +
-		"  `/watched`: prints list of watched movies\n" +
-		"  `/watched username`: prints list of movies watched by username\n" +
-		"  `/draw n=1`: draws n movies at random (default n=1)\n" +
-		"  `/save`: force save everything\n" +
-		"  `/ranking`: shows top movie-watchers\n" +
-		"**Important:** before `/add`-ing, `/query` first to make sure it's the right movie!"
-	msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
-	msg.ReplyToMessageID = u.Message.MessageID
-	msg.ParseMode = tgbotapi.ModeMarkdown
-	bot.Send(msg)
+	C := chat(u)
+	for _, k := range helpSections {
+		msg := tgbotapi.NewMessage(u.Message.Chat.ID, C.T(k))
+		msg.ReplyToMessageID = u.Message.MessageID
+		msg.ParseMode = tgbotapi.ModeMarkdown
+		bot.Send(msg)
+	}
 }
 
 func loop(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
 	fmt.Printf("[%s|%s] %s\n", u.Message.Chat.Title, u.Message.From.UserName, u.Message.Text)
 	RegisterUser(u)
 	RemoveLeavers(u)
-	if u.Message.IsCommand() {
+	if isImport(u.Message) {
+		log.Printf("Command /import activated")
+		Import(bot, u)
+	} else if u.Message.IsCommand() {
+		back, ok := forList(bot, u)
+		if !ok {
+			return
+		}
+		defer back()
 		cmd := u.Message.Command()
 		switch cmd {
 		case CmdAll:
@@ -82,7 +83,73 @@ func loop(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
 		case CmdRanking:
 			log.Printf("Command /rank activated")
 			Ranking(bot, u)
+		case CmdRate:
+			log.Printf("Command /rate activated")
+			Rate(bot, u)
+		case CmdExport:
+			log.Printf("Command /export activated")
+			Export(bot, u)
+		case CmdImport:
+			log.Printf("Command /import activated")
+			Import(bot, u)
+		case CmdRefresh:
+			log.Printf("Command /refresh activated")
+			Refresh(bot, u)
+		case CmdTonight:
+			log.Printf("Command /tonight activated")
+			Tonight(bot, u)
+		case CmdVote:
+			log.Printf("Command /vote activated")
+			Vote(bot, u)
+		case CmdTop:
+			log.Printf("Command /top activated")
+			Top(bot, u)
+		case CmdRecommend:
+			log.Printf("Command /recommend activated")
+			Recommend(bot, u)
+		case CmdLog:
+			log.Printf("Command /log activated")
+			Log(bot, u)
+		case CmdTag:
+			log.Printf("Command /tag activated")
+			Tag(bot, u)
+		case CmdUntag:
+			log.Printf("Command /untag activated")
+			Untag(bot, u)
+		case CmdNote:
+			log.Printf("Command /note activated")
+			Note(bot, u)
+		case CmdList:
+			log.Printf("Command /list activated")
+			List(bot, u)
+		case CmdGroups:
+			log.Printf("Command /groups activated")
+			Groups(bot, u)
+		case CmdMine:
+			log.Printf("Command /mine activated")
+			Mine(bot, u)
+		case CmdAddTo:
+			log.Printf("Command /addto activated")
+			AddTo(bot, u)
+		case CmdCountry:
+			log.Printf("Command /country activated")
+			Country(bot, u)
+		case CmdNotify:
+			log.Printf("Command /notify activated")
+			Notify(bot, u)
+		case CmdDedupe:
+			log.Printf("Command /dedupe activated")
+			Dedupe(bot, u)
+		case CmdLanguage:
+			log.Printf("Command /language activated")
+			Language(bot, u)
+		case CmdStart:
+			log.Printf("Command /start activated")
+			Start(bot, u)
 		}
+	} else if isComment(u) {
+		log.Printf("Comment on a movie's card")
+		Discuss(bot, u)
 	}
 	gcIterations++
 	if gcIterations%MaxGCIterations == 0 {
@@ -113,15 +180,35 @@ func main() {
 
 	log.Printf("Authorized on account %s", bot.Self.UserName)
 
+	loadAllChats()
+	loadProvider("availability.json")
+
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 
 	updates, err := bot.GetUpdatesChan(u)
 
-	for update := range updates {
-		if update.Message == nil {
-			continue
+	ticker := time.NewTicker(alertTick)
+	checked := make(chan *alertCheck)
+	for {
+		select {
+		case update := <-updates:
+			if update.InlineQuery != nil {
+				Inline(bot, &update)
+				continue
+			}
+			if update.CallbackQuery != nil {
+				Callback(bot, &update)
+				continue
+			}
+			if update.Message == nil {
+				continue
+			}
+			loop(bot, &update)
+		case <-ticker.C:
+			scheduleChecks(checked)
+		case a := <-checked:
+			announce(bot, a)
 		}
-		loop(bot, &update)
 	}
 }
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"io/ioutil"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const CmdNote = "note"
+
+const (
+	// maxCards is how many /show cards we remember, so replies to them become comments.
+	maxCards = 500
+	// shownNotes is how many of the latest notes and comments a card shows.
+	shownNotes = 3
+	// maxNoteLen is how much of each note a card shows.
+	maxNoteLen = 120
+)
+
+// Comment is something someone said about a movie, either with /note or by replying to its card.
+type Comment struct {
+	Time time.Time
+	User string
+	Text string
+}
+
+var wordRegexp = regexp.MustCompile(`\S+`)
+
+// noteTarget finds the movie a note is about, given either by its index or by the longest start
+// of args matching a single title, returning its index (or -1 if none) and the note.
+func noteTarget(C *Chat, args string) (int, string) {
+	W := wordRegexp.FindAllStringIndex(args, -1)
+	if len(W) == 0 {
+		return -1, ""
+	}
+	if i, err := strconv.Atoi(args[W[0][0]:W[0][1]]); err == nil {
+		if i < 0 || i >= len(C.movies) {
+			return -1, ""
+		}
+		return i, strings.TrimSpace(args[W[0][1]:])
+	}
+	for k := len(W); k > 0; k-- {
+		if I := findMovies(args[:W[k-1][1]], C.movies); len(I) == 1 {
+			return I[0], strings.TrimSpace(args[W[k-1][1]:])
+		}
+	}
+	return -1, ""
+}
+
+// shorten cuts s to n runes at most.
+func shorten(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-3]) + "..."
+	}
+	return s
+}
+
+// describeNotes writes the latest comments of L under title, for a movie's card.
+func describeNotes(title string, L []Comment) string {
+	if len(L) == 0 {
+		return ""
+	}
+	s := fmt.Sprintf("\n%s (%d):", title, len(L))
+	if len(L) > shownNotes {
+		L = L[len(L)-shownNotes:]
+	}
+	for _, c := range L {
+		s += fmt.Sprintf("\n  @%s: %s", c.User, shorten(c.Text, maxNoteLen))
+	}
+	return s
+}
+
+func Note(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	C := chat(u)
+	var s string
+	i, text := noteTarget(C, u.Message.CommandArguments())
+	if i < 0 {
+		s = C.T("note.usage")
+	} else if m := &C.movies[i]; text == "" {
+		s = C.T("note.none", m.Title, m.Year)
+		if len(m.Notes) > 0 {
+			s = C.T("note.header", m.Title, m.Year)
+			for _, c := range m.Notes {
+				s += fmt.Sprintf("\n  @%s (%s): %s", c.User, c.Time.Format("2006-01-02"), c.Text)
+			}
+		}
+	} else {
+		before := snapshot(m)
+		m.Notes = append(m.Notes, Comment{time.Now(), u.Message.From.UserName, text})
+		record(C, u, ActNote, before, m)
+		saveMovies(C)
+		s = C.T("note.done", m.Title, m.Year)
+	}
+	msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
+	msg.ReplyToMessageID = u.Message.MessageID
+	bot.Send(msg)
+}
+
+// rememberCard remembers that message id is the card of the movie with IMDb ID movie, forgetting
+// the oldest cards beyond maxCards.
+func rememberCard(C *Chat, id int, movie string) {
+	if C.cards == nil {
+		C.cards = make(map[int]string)
+	}
+	C.cards[id] = movie
+	if len(C.cards) > maxCards {
+		I := make([]int, 0, len(C.cards))
+		for k := range C.cards {
+			I = append(I, k)
+		}
+		sort.Ints(I)
+		for _, k := range I[:len(I)-maxCards] {
+			delete(C.cards, k)
+		}
+	}
+	saveCards(C)
+}
+
+// cardOf returns the movie whose card m replies to, if any.
+func cardOf(C *Chat, m *tgbotapi.Message) *Entry {
+	if m.ReplyToMessage == nil {
+		return nil
+	}
+	id, e := C.cards[m.ReplyToMessage.MessageID]
+	if !e {
+		return nil
+	}
+	for _, L := range [][]Entry{C.movies, C.watchedMovies} {
+		for i := range L {
+			if L[i].ID == id {
+				return &L[i]
+			}
+		}
+	}
+	return nil
+}
+
+// isComment returns whether m replies to a movie's card.
+func isComment(u *tgbotapi.Update) bool {
+	return u.Message.Text != "" && cardOf(chat(u), u.Message) != nil
+}
+
+// Discuss saves a reply to a movie's card as a comment on the movie.
+func Discuss(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	C := chat(u)
+	m := cardOf(C, u.Message)
+	before := snapshot(m)
+	m.Comments = append(m.Comments, Comment{time.Now(), u.Message.From.UserName, u.Message.Text})
+	record(C, u, ActComment, before, m)
+	saveMovies(C)
+}
+
+func saveCards(C *Chat) {
+	f, err := os.Create(C.prefix + "cards.json")
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	defer f.Close()
+	b, err := json.Marshal(C.cards)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	_, err = f.Write(b)
+	if err != nil {
+		log.Printf("Error: %v", err)
+	}
+}
+
+func loadCards(C *Chat) {
+	f, err := os.Open(C.prefix + "cards.json")
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	if len(b) < 5 {
+		return
+	}
+	err = json.Unmarshal(b, &C.cards)
+	if err != nil {
+		log.Printf("Error: %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	CmdGroups = "groups"
+	CmdMine   = "mine"
+	CmdAddTo  = "addto"
+)
+
+// sender returns the Telegram ID of whoever sent u, or zero if we can't tell.
+func sender(u *tgbotapi.Update) int {
+	if u.Message.From == nil {
+		return 0
+	}
+	return u.Message.From.ID
+}
+
+// groups returns the group chats the user with Telegram ID id is in, sorted by title.
+func groups(id int) []*Chat {
+	var G []*Chat
+	for _, C := range chatMap {
+		if C.isMember(id) && C.id < 0 {
+			G = append(G, C)
+		}
+	}
+	sort.Slice(G, func(i, j int) bool { return G[i].settings.Title < G[j].settings.Title })
+	return G
+}
+
+// group finds the group of the user with Telegram ID id given either by its position in
+// groups(id) or by its chat ID.
+func group(id int, arg string) *Chat {
+	n, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return nil
+	}
+	G := groups(id)
+	for i, C := range G {
+		if int64(i) == n || C.id == n {
+			return C
+		}
+	}
+	return nil
+}
+
+// unseen returns the indices of the movies in C's list usr hasn't watched.
+func unseen(C *Chat, usr string) []int {
+	var I []int
+	for i := range C.movies {
+		if !hasWatched(&C.movies[i], usr) {
+			I = append(I, i)
+		}
+	}
+	return I
+}
+
+func Groups(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	P := chat(u)
+	usr := u.Message.From.UserName
+	var s string
+	G := groups(sender(u))
+	if !u.Message.Chat.IsPrivate() {
+		s = P.H("groups.private")
+	} else if sender(u) == 0 {
+		s = P.H("private.unknown")
+	} else if len(G) == 0 {
+		s = P.H("groups.none")
+	} else {
+		s = P.H("groups.header")
+		for i, C := range G {
+			s += P.HN("groups.item", len(C.movies), i, escape(C.settings.Title), len(C.movies),
+				len(unseen(C, usr)))
+		}
+		s += P.H("groups.footer")
+	}
+	msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
+	msg.ReplyToMessageID = u.Message.MessageID
+	sendHTML(bot, msg)
+}
+
+func Mine(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	P := chat(u)
+	usr := u.Message.From.UserName
+	var s string
+	if !u.Message.Chat.IsPrivate() {
+		s = P.T("mine.private")
+	} else if sender(u) == 0 {
+		s = P.T("private.unknown")
+	} else {
+		if I := unseen(P, usr); len(I) > 0 {
+			s += P.T("mine.own")
+			for _, i := range I {
+				s += fmt.Sprintf("  %d. %s (%d)\n", i, P.movies[i].Title, P.movies[i].Year)
+			}
+		}
+		for _, C := range groups(sender(u)) {
+			if I := unseen(C, usr); len(I) > 0 {
+				s += C.settings.Title + ":\n"
+				for _, i := range I {
+					s += fmt.Sprintf("  %d. %s (%d)\n", i, C.movies[i].Title, C.movies[i].Year)
+				}
+			}
+		}
+		if s == "" {
+			s = P.T("mine.none")
+		}
+	}
+	msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
+	msg.ReplyToMessageID = u.Message.MessageID
+	bot.Send(msg)
+}
+
+func AddTo(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	P := chat(u)
+	var s string
+	var C *Chat
+	var e *Entry
+	args := strings.Fields(u.Message.CommandArguments())
+	if !u.Message.Chat.IsPrivate() {
+		s = P.H("addto.private")
+		goto send
+	}
+	if sender(u) == 0 {
+		s = P.H("private.unknown")
+		goto send
+	}
+	if len(args) < 2 {
+		s = P.H("addto.usage")
+		goto send
+	}
+	if C = group(sender(u), args[0]); C == nil {
+		s = P.H("addto.group")
+		goto send
+	}
+	if q := strings.Join(args[1:], " "); imdbIDRegexp.FindString(q) == q {
+		if e = RetrieveID(q); e != nil {
+			Details(e)
+		}
+	} else {
+		e = Lookup(q)
+	}
+	if e == nil {
+		s = P.H("add.none")
+	} else if addEntry(C, e, u) < 0 {
+		s = P.H("addto.present", escape(e.Title), e.Year, escape(C.settings.Title))
+	} else {
+		s = P.H("addto.done", escape(e.Title), e.Year, escape(C.settings.Title))
+	}
+send:
+	msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
+	msg.ReplyToMessageID = u.Message.MessageID
+	sendHTML(bot, msg)
+}
+
+// offerGroups asks, in private, whether e should go to one of the sender's groups too.
+func offerGroups(bot *tgbotapi.BotAPI, u *tgbotapi.Update, e *Entry) {
+	G := groups(sender(u))
+	if !u.Message.Chat.IsPrivate() || len(G) == 0 {
+		return
+	}
+	P := chat(u)
+	var K [][]tgbotapi.InlineKeyboardButton
+	for _, C := range G {
+		data := fmt.Sprintf("%s %d %s", CmdAddTo, C.id, e.ID)
+		K = append(K, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(P.T("addto.button", C.settings.Title), data)))
+	}
+	msg := tgbotapi.NewMessage(u.Message.Chat.ID, P.T("addto.offer"))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(K...)
+	bot.Send(msg)
+}
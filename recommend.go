@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const CmdRecommend = "recommend"
+
+const (
+	maxRecommendations = 5
+	unratedWeight      = 0.3
+	directorWeight     = 2
+	decadeWeight       = 0.5
+	sourceWeight       = 1.5
+)
+
+// Taste is how much a group likes each genre, director and decade, from -1 (hates) to 1 (loves)
+// per movie watched.
+type Taste struct {
+	Genres    map[string]float64
+	Directors map[string]float64
+	Decades   map[int]float64
+}
+
+// liking returns how much the group liked m, from -1 to 1, going by their ratings. Movies
+// nobody rated count as mildly liked, since the group chose to watch them.
+func liking(m *Entry) float64 {
+	if len(m.Ratings) == 0 {
+		return unratedWeight
+	}
+	var s float64
+	for _, r := range m.Ratings {
+		s += float64(r)
+	}
+	return (s/float64(len(m.Ratings)) - 5.5) / 4.5
+}
+
+// watched returns every movie someone in the chat has watched.
+func watched(C *Chat) []*Entry {
+	var W []*Entry
+	for i := range C.watchedMovies {
+		W = append(W, &C.watchedMovies[i])
+	}
+	for i := range C.movies {
+		if len(C.movies[i].WatchedBy) > 0 {
+			W = append(W, &C.movies[i])
+		}
+	}
+	return W
+}
+
+// taste builds the group's Taste from the movies in W.
+func taste(W []*Entry) *Taste {
+	T := &Taste{make(map[string]float64), make(map[string]float64), make(map[int]float64)}
+	for _, m := range W {
+		w := liking(m)
+		for _, g := range m.Genres {
+			T.Genres[g] += w
+		}
+		for _, d := range m.Directors {
+			T.Directors[d] += w
+		}
+		T.Decades[m.Year-m.Year%10] += w
+	}
+	return T
+}
+
+// score returns how much a group with taste T should like e.
+func (T *Taste) score(e *Entry) float64 {
+	var s float64
+	for _, g := range e.Genres {
+		s += T.Genres[g] / float64(len(e.Genres))
+	}
+	for _, d := range e.Directors {
+		s += directorWeight * T.Directors[d]
+	}
+	return s + decadeWeight*T.Decades[e.Year-e.Year%10]
+}
+
+// recommendation is a candidate title and the watched movie that led to it.
+type recommendation struct {
+	e      *Entry
+	score  float64
+	source *Entry
+}
+
+// prefetching holds the IMDb IDs being fetched in the background, so they are fetched once.
+var prefetching sync.Map
+
+// prefetch fetches the metadata of the titles with the given IMDb IDs in the background, so
+// later recommendations can use them.
+func prefetch(ids []string) {
+	var P []string
+	for _, id := range ids {
+		if _, loaded := prefetching.LoadOrStore(id, true); !loaded {
+			P = append(P, id)
+		}
+	}
+	go resolveAll(len(P), func(i int) *Entry {
+		defer prefetching.Delete(P[i])
+		e := RetrieveID(P[i])
+		if e != nil {
+			Details(e)
+		}
+		return e
+	})
+}
+
+// recommend ranks the titles IMDb deems similar to what the chat watched, using only cached
+// metadata. It returns the IMDb IDs of candidates that are not cached yet too.
+func recommend(C *Chat) ([]recommendation, []string) {
+	W := watched(C)
+	T := taste(W)
+	known := make(map[string]bool)
+	for _, L := range [][]Entry{C.movies, C.watchedMovies} {
+		for i := range L {
+			known[L[i].ID] = true
+		}
+	}
+	R := make(map[string]*recommendation)
+	var missing []string
+	for _, m := range W {
+		w := liking(m)
+		if w <= 0 {
+			continue
+		}
+		for _, id := range m.Similar {
+			if known[id] {
+				continue
+			}
+			r, e := R[id]
+			if !e {
+				c, ok := CachedEntry(id)
+				if !ok {
+					known[id] = true
+					missing = append(missing, id)
+					continue
+				}
+				r = &recommendation{e: c, score: T.score(c)}
+				R[id] = r
+			}
+			r.score += sourceWeight * w
+			if r.source == nil || liking(r.source) < w {
+				r.source = m
+			}
+		}
+	}
+	S := make([]recommendation, 0, len(R))
+	for _, r := range R {
+		S = append(S, *r)
+	}
+	sort.Slice(S, func(i, j int) bool { return S[i].score > S[j].score })
+	return S, missing
+}
+
+func Recommend(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	C := chat(u)
+	S, missing := recommend(C)
+	if len(missing) > 0 {
+		log.Printf("Prefetching %d titles for recommendations", len(missing))
+		prefetch(missing)
+	}
+	var s string
+	if len(S) == 0 {
+		s = C.H("recommend.none")
+		if len(missing) > 0 {
+			s = C.H("recommend.pending")
+		}
+	} else {
+		s = C.H("recommend.header")
+		for i, r := range S {
+			if i == maxRecommendations {
+				break
+			}
+			s += fmt.Sprintf("  %d. %s (%d)", i+1, escape(r.e.Title), r.e.Year)
+			if len(r.e.Genres) > 0 {
+				s += " - " + escape(strings.Join(r.e.Genres, ", "))
+			}
+			s += C.H("recommend.because", escape(r.source.Title), r.source.Year)
+		}
+		s += C.H("recommend.footer")
+	}
+	msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
+	msg.ReplyToMessageID = u.Message.MessageID
+	sendHTML(bot, msg)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"html"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// Messages listing titles and usernames are sent as HTML, since any of them may hold characters
+// Markdown takes as markup, and Telegram drops messages it can't parse. Escaping makes them safe
+// in HTML, and if Telegram still can't parse one, we send it again as plain text.
+
+var (
+	codeRegexp = regexp.MustCompile("`([^`]*)`")
+	tagsRegexp = regexp.MustCompile(`<[^>]*>`)
+)
+
+// escape makes s, which may come from anyone, safe to show in an HTML message.
+func escape(s string) string {
+	return html.EscapeString(s)
+}
+
+// markup turns a message of the catalog, written in Markdown with `code` spans, into HTML.
+func markup(s string) string {
+	return codeRegexp.ReplaceAllString(escape(s), "<code>$1</code>")
+}
+
+// plain turns the HTML message s into plain text.
+func plain(s string) string {
+	return html.UnescapeString(tagsRegexp.ReplaceAllString(s, ""))
+}
+
+// H returns the message key in C's language as HTML, formatted with args, which must be HTML
+// already.
+func (C *Chat) H(key string, args ...interface{}) string {
+	return format(markup(C.message(key)), args)
+}
+
+// HN returns the plural form of the message key for n things in C's language as HTML, formatted
+// with args, which must be HTML already.
+func (C *Chat) HN(key string, n int, args ...interface{}) string {
+	return C.H(C.plural(key, n), args...)
+}
+
+// sendHTML sends msg, whose text is HTML, sending it again as plain text if Telegram can't parse
+// it.
+func sendHTML(bot *tgbotapi.BotAPI, msg tgbotapi.MessageConfig) (tgbotapi.Message, error) {
+	msg.ParseMode = tgbotapi.ModeHTML
+	sent, err := bot.Send(msg)
+	if err != nil && strings.Contains(err.Error(), "can't parse entities") {
+		log.Printf("Error: %v", err)
+		log.Printf("Telegram could not parse the message, sending it as plain text.")
+		msg.ParseMode = ""
+		msg.Text = plain(msg.Text)
+		sent, err = bot.Send(msg)
+	}
+	if err != nil {
+		log.Printf("Error: %v", err)
+	}
+	return sent, err
+}
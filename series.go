@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Kinds of titles an Entry can be.
+const (
+	TypeMovie      = "movie"
+	TypeSeries     = "series"
+	TypeMiniSeries = "mini-series"
+	TypeShort      = "short"
+)
+
+// titleTypes maps the kinds of titles IMDb suggests to our own.
+var titleTypes = map[string]string{
+	"tvSeries":     TypeSeries,
+	"tvMiniSeries": TypeMiniSeries,
+	"short":        TypeShort,
+	"tvShort":      TypeShort,
+}
+
+// Episode is a single episode of a series.
+type Episode struct {
+	Season  int
+	Episode int
+}
+
+func (ep Episode) String() string {
+	return fmt.Sprintf("S%dE%d", ep.Season, ep.Episode)
+}
+
+var episodeRegexp = regexp.MustCompile(`(?i)^s(\d+)e(\d+)$`)
+
+// takeEpisode reads an episode written as s2e3 out of args, returning the other arguments.
+func takeEpisode(args string) (string, *Episode) {
+	F := strings.Fields(args)
+	for i, f := range F {
+		if M := episodeRegexp.FindStringSubmatch(f); M != nil {
+			s, _ := strconv.Atoi(M[1])
+			e, _ := strconv.Atoi(M[2])
+			return strings.Join(append(F[:i], F[i+1:]...), " "), &Episode{s, e}
+		}
+	}
+	return args, nil
+}
+
+// isSeries returns whether e has episodes.
+func (e *Entry) isSeries() bool {
+	return e.Type == TypeSeries || e.Type == TypeMiniSeries
+}
+
+// lastEpisode returns the last episode of e, if we know how many episodes it has.
+func (e *Entry) lastEpisode() (Episode, bool) {
+	if len(e.Seasons) == 0 {
+		return Episode{}, false
+	}
+	return Episode{len(e.Seasons), e.Seasons[len(e.Seasons)-1]}, true
+}
+
+// next returns the episode after ep, or false if ep was the last one.
+func (e *Entry) next(ep Episode) (Episode, bool) {
+	if ep.Season < 1 {
+		return Episode{1, 1}, true
+	}
+	if ep.Season > len(e.Seasons) || ep.Episode < e.Seasons[ep.Season-1] {
+		return Episode{ep.Season, ep.Episode + 1}, true
+	}
+	if ep.Season < len(e.Seasons) {
+		return Episode{ep.Season + 1, 1}, true
+	}
+	return Episode{}, false
+}
+
+// valid returns whether e has episode ep, as far as we know.
+func (e *Entry) valid(ep Episode) bool {
+	if ep.Season < 1 || ep.Episode < 1 {
+		return false
+	}
+	return len(e.Seasons) == 0 || (ep.Season <= len(e.Seasons) && ep.Episode <= e.Seasons[ep.Season-1])
+}
+
+// finished returns whether usr watched every episode of e. A series counts as watched once each
+// member finishes it.
+func (e *Entry) finished(usr string) bool {
+	ep, ok := e.Progress[usr]
+	if !ok {
+		return false
+	}
+	_, more := e.next(ep)
+	return len(e.Seasons) > 0 && !more
+}
+
+// progress describes how far each member got in e, in C's language.
+func (e *Entry) progress(C *Chat) string {
+	var s string
+	for usr, ep := range e.Progress {
+		if n, more := e.next(ep); more {
+			s += C.T("show.progress.next", usr, ep, n)
+		} else {
+			s += C.T("show.progress.done", usr)
+		}
+	}
+	return s
+}
@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+func TestNextEpisode(t *testing.T) {
+	e := &Entry{Type: TypeSeries, Seasons: []int{3, 2}}
+	tests := []struct {
+		ep   Episode
+		want Episode
+		ok   bool
+	}{
+		{Episode{}, Episode{1, 1}, true},
+		{Episode{1, 1}, Episode{1, 2}, true},
+		{Episode{1, 3}, Episode{2, 1}, true},
+		{Episode{2, 1}, Episode{2, 2}, true},
+		{Episode{2, 2}, Episode{}, false},
+		{Episode{3, 1}, Episode{3, 2}, true},
+	}
+	for _, test := range tests {
+		got, ok := e.next(test.ep)
+		if got != test.want || ok != test.ok {
+			t.Errorf("next(%s) = %s, %v, want %s, %v", test.ep, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestValidEpisode(t *testing.T) {
+	e := &Entry{Type: TypeSeries, Seasons: []int{3, 2}}
+	unknown := &Entry{Type: TypeSeries}
+	tests := []struct {
+		e    *Entry
+		ep   Episode
+		want bool
+	}{
+		{e, Episode{1, 1}, true},
+		{e, Episode{1, 3}, true},
+		{e, Episode{1, 4}, false},
+		{e, Episode{2, 2}, true},
+		{e, Episode{3, 1}, false},
+		{e, Episode{0, 1}, false},
+		{e, Episode{1, 0}, false},
+		{unknown, Episode{7, 12}, true},
+		{unknown, Episode{0, 1}, false},
+	}
+	for _, test := range tests {
+		if got := test.e.valid(test.ep); got != test.want {
+			t.Errorf("valid(%s) with seasons %v = %v, want %v", test.ep, test.e.Seasons, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	CmdTag   = "tag"
+	CmdUntag = "untag"
+)
+
+const (
+	// maxTagChoices is how many tags are suggested as buttons at most.
+	maxTagChoices = 8
+	// maxTagLen is how many characters of a tag we keep, so tags fit in a button's data.
+	maxTagLen = 16
+	// maxCallbackData is how many bytes of data Telegram lets a button carry.
+	maxCallbackData = 64
+)
+
+var tagRegexp = regexp.MustCompile(`^[\p{L}\p{N}_-]+!?$`)
+
+// takeTags reads the tags, written as #tag, out of args, returning the other arguments. Tags
+// ending in ! are meant exactly as written, and are not completed. Tags are cut to maxTagLen.
+func takeTags(args string) (string, []string) {
+	var rest, T []string
+	for _, a := range strings.Fields(args) {
+		if t := strings.ToLower(strings.TrimPrefix(a, "#")); a[0] == '#' && tagRegexp.MatchString(t) {
+			exact := strings.HasSuffix(t, "!")
+			if t = strings.TrimSuffix(t, "!"); len([]rune(t)) > maxTagLen {
+				t = string([]rune(t)[:maxTagLen])
+			}
+			if exact {
+				t += "!"
+			}
+			if !inList(t, T) {
+				T = append(T, t)
+			}
+		} else {
+			rest = append(rest, a)
+		}
+	}
+	return strings.Join(rest, " "), T
+}
+
+func hasTag(e *Entry, t string) bool {
+	return inList(t, e.Tags)
+}
+
+// chatTags returns every tag used in C, the most used first.
+func chatTags(C *Chat) []string {
+	N := make(map[string]int)
+	for _, L := range [][]Entry{C.movies, C.watchedMovies} {
+		for i := range L {
+			for _, t := range L[i].Tags {
+				N[t]++
+			}
+		}
+	}
+	T := make([]string, 0, len(N))
+	for t := range N {
+		T = append(T, t)
+	}
+	sort.Slice(T, func(i, j int) bool { return N[T[i]] > N[T[j]] || (N[T[i]] == N[T[j]] && T[i] < T[j]) })
+	return T
+}
+
+// completions returns the tags of known that start with t, other than t itself.
+func completions(known []string, t string) []string {
+	var C []string
+	for _, k := range known {
+		if k != t && strings.HasPrefix(k, t) {
+			C = append(C, k)
+		}
+	}
+	return C
+}
+
+// tagButtons returns a keyboard that runs cmd on the i-th movie with each of the tags of T.
+func tagButtons(C *Chat, cmd string, i int, T []string) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	var n int
+	for _, t := range T {
+		if n == maxTagChoices {
+			break
+		}
+		// Tags of wide characters, or from before maxTagLen, may still not fit in a button.
+		data := fmt.Sprintf("%s %d #%s%s", cmd, i, t, listArg(C))
+		if len(data) > maxCallbackData {
+			continue
+		}
+		n++
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("#"+strings.TrimSuffix(t, "!"), data))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if row != nil {
+		rows = append(rows, row)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// tagCommand returns cmd followed by as many of the tags of T as fit in the data of pickMovie's
+// buttons, which add a movie's index and C's list argument to it.
+func tagCommand(C *Chat, cmd string, T []string) string {
+	n := len(cmd) + len(fmt.Sprintf(" %d", len(C.movies))) + len(listArg(C))
+	for _, t := range T {
+		if n += len(" #" + t); n > maxCallbackData {
+			break
+		}
+		cmd += " #" + t
+	}
+	return cmd
+}
+
+func hashtags(T []string) string {
+	if len(T) == 0 {
+		return ""
+	}
+	return "#" + strings.Join(T, " #")
+}
+
+func Tag(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	C := chat(u)
+	args, T := takeTags(u.Message.CommandArguments())
+	i, m := pickMovie(bot, u, C, tagCommand(C, CmdTag, T), args)
+	if m == nil {
+		return
+	}
+	known := chatTags(C)
+	before := snapshot(m)
+	var added, suggest []string
+	for _, t := range T {
+		t, exact := strings.TrimSuffix(t, "!"), strings.HasSuffix(t, "!")
+		if c := completions(known, t); !exact && !inList(t, known) && len(c) > 0 {
+			for _, k := range append(c, t+"!") {
+				if !inList(k, suggest) {
+					suggest = append(suggest, k)
+				}
+			}
+		} else if !hasTag(m, t) {
+			m.Tags = append(m.Tags, t)
+			added = append(added, t)
+		}
+	}
+	if len(T) == 0 {
+		for _, t := range known {
+			if !hasTag(m, t) {
+				suggest = append(suggest, t)
+			}
+		}
+	}
+	var s string
+	if len(added) > 0 {
+		record(C, u, ActTag, before, m)
+		saveMovies(C)
+		s = C.T("tag.done", m.Title, m.Year, hashtags(added))
+	} else if len(T) > 0 && len(suggest) == 0 {
+		s = C.T("tag.present", m.Title, m.Year)
+	} else if len(suggest) == 0 {
+		s = C.T("tag.usage", m.Title, m.Year, i)
+	}
+	var msg tgbotapi.MessageConfig
+	if len(suggest) > 0 {
+		msg = tgbotapi.NewMessage(u.Message.Chat.ID, strings.TrimSpace(s+"\n"+C.T("tag.which")))
+		msg.ReplyMarkup = tagButtons(C, CmdTag, i, suggest)
+	} else {
+		msg = tgbotapi.NewMessage(u.Message.Chat.ID, s)
+	}
+	msg.ReplyToMessageID = u.Message.MessageID
+	bot.Send(msg)
+}
+
+func Untag(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	C := chat(u)
+	args, T := takeTags(u.Message.CommandArguments())
+	i, m := pickMovie(bot, u, C, tagCommand(C, CmdUntag, T), args)
+	if m == nil {
+		return
+	}
+	var msg tgbotapi.MessageConfig
+	if len(T) == 0 {
+		if len(m.Tags) == 0 {
+			msg = tgbotapi.NewMessage(u.Message.Chat.ID, C.T("untag.none", m.Title, m.Year))
+		} else {
+			msg = tgbotapi.NewMessage(u.Message.Chat.ID, C.T("untag.which"))
+			msg.ReplyMarkup = tagButtons(C, CmdUntag, i, m.Tags)
+		}
+	} else {
+		before := snapshot(m)
+		var removed []string
+		for _, t := range T {
+			t = strings.TrimSuffix(t, "!")
+			if j := indexOf(t, m.Tags); j >= 0 {
+				m.Tags = append(m.Tags[:j], m.Tags[j+1:]...)
+				removed = append(removed, t)
+			}
+		}
+		s := C.T("untag.missing", m.Title, m.Year)
+		if len(removed) > 0 {
+			record(C, u, ActUntag, before, m)
+			saveMovies(C)
+			s = C.T("untag.done", hashtags(removed), m.Title, m.Year)
+		}
+		msg = tgbotapi.NewMessage(u.Message.Chat.ID, s)
+	}
+	msg.ReplyToMessageID = u.Message.MessageID
+	bot.Send(msg)
+}
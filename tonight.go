@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	CmdTonight = "tonight"
+	CmdVote    = "vote"
+	CmdTop     = "top"
+)
+
+const (
+	audienceTTL = 12 * time.Hour
+	maxTop      = 10
+)
+
+// Audience is who is watching tonight. It is forgotten after audienceTTL.
+type Audience struct {
+	Members []string
+	Until   time.Time
+}
+
+// audience returns who is watching tonight, or nil if nobody said so recently.
+func (C *Chat) audience() []string {
+	if len(C.tonight.Members) == 0 || time.Now().After(C.tonight.Until) {
+		return nil
+	}
+	return C.tonight.Members
+}
+
+func inList(usr string, L []string) bool {
+	return indexOf(usr, L) >= 0
+}
+
+// votesFrom returns how many of A voted for m, or how many votes m has if A is nil.
+func votesFrom(m *Entry, A []string) int {
+	if A == nil {
+		return len(m.Votes)
+	}
+	var v int
+	for _, usr := range m.Votes {
+		if inList(usr, A) {
+			v++
+		}
+	}
+	return v
+}
+
+// forAudience keeps the indices in K of the movies the fewest members of A have seen (ideally
+// none), sorted by their votes.
+func forAudience(C *Chat, K []int, A []string) []int {
+	seen := make(map[int]int, len(K))
+	least := len(A)
+	for _, i := range K {
+		for _, usr := range A {
+			if hasWatched(&C.movies[i], usr) {
+				seen[i]++
+			}
+		}
+		least = min(least, seen[i])
+	}
+	var I []int
+	for _, i := range K {
+		if seen[i] == least {
+			I = append(I, i)
+		}
+	}
+	sort.SliceStable(I, func(a, b int) bool {
+		return votesFrom(&C.movies[I[a]], A) > votesFrom(&C.movies[I[b]], A)
+	})
+	return I
+}
+
+func Tonight(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	C := chat(u)
+	usr := u.Message.From.UserName
+	args := strings.Fields(u.Message.CommandArguments())
+	var s string
+	var unknown []string
+	A := C.audience()
+	switch {
+	case len(args) == 0:
+		msg := tgbotapi.NewMessage(u.Message.Chat.ID, C.T("tonight.ask"))
+		if A != nil {
+			msg.Text += C.T("tonight.sofar", strings.Join(A, ", @"))
+		}
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(C.T("tonight.in"), CmdTonight+" in"),
+			tgbotapi.NewInlineKeyboardButtonData(C.T("tonight.out"), CmdTonight+" out"),
+		))
+		msg.ReplyToMessageID = u.Message.MessageID
+		bot.Send(msg)
+		return
+	case args[0] == "in":
+		if !inList(usr, A) {
+			A = append(A, usr)
+		}
+	case args[0] == "out":
+		var B []string
+		for _, a := range A {
+			if !strings.EqualFold(a, usr) {
+				B = append(B, a)
+			}
+		}
+		A = B
+	case args[0] == "clear":
+		A = nil
+	default:
+		A = nil
+		for _, a := range usernames(strings.Join(args, ",")) {
+			if c, e := C.User(strings.ToLower(a)); e {
+				A = append(A, c.UserName)
+			} else {
+				unknown = append(unknown, a)
+			}
+		}
+	}
+	C.tonight = Audience{A, time.Now().Add(audienceTTL)}
+	saveTonight(C)
+	if len(A) == 0 {
+		s = C.H("tonight.none")
+	} else {
+		s = C.H("tonight.set", escape(strings.Join(A, ", @")))
+	}
+	if unknown != nil {
+		s += "\n" + C.H("watched.unknown", escape(strings.Join(unknown, ", ")))
+	}
+	msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
+	msg.ReplyToMessageID = u.Message.MessageID
+	sendHTML(bot, msg)
+}
+
+func Vote(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	C := chat(u)
+	usr := u.Message.From.UserName
+	var s string
+	for _, i := range pickMovies(bot, u, C, CmdVote, u.Message.CommandArguments()) {
+		if i < 0 || i >= len(C.movies) {
+			continue
+		}
+		m := &C.movies[i]
+		before := snapshot(m)
+		if j := indexOf(usr, m.Votes); j >= 0 {
+			m.Votes = append(m.Votes[:j], m.Votes[j+1:]...)
+			record(C, u, ActUnvote, before, m)
+			s += C.T("vote.removed", m.Title, m.Year)
+		} else {
+			m.Votes = append(m.Votes, usr)
+			record(C, u, ActVote, before, m)
+			s += C.T("vote.added", m.Title, m.Year)
+		}
+	}
+	if s == "" {
+		return
+	}
+	saveMovies(C)
+	msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
+	msg.ReplyToMessageID = u.Message.MessageID
+	bot.Send(msg)
+}
+
+func indexOf(usr string, L []string) int {
+	for i, s := range L {
+		if strings.EqualFold(s, usr) {
+			return i
+		}
+	}
+	return -1
+}
+
+func Top(bot *tgbotapi.BotAPI, u *tgbotapi.Update) {
+	C := chat(u)
+	A := C.audience()
+	I := make([]int, len(C.movies))
+	for i := range I {
+		I[i] = i
+	}
+	if A != nil {
+		I = forAudience(C, I, A)
+	} else {
+		sort.SliceStable(I, func(a, b int) bool {
+			return len(C.movies[I[a]].Votes) > len(C.movies[I[b]].Votes)
+		})
+	}
+	s := C.H("top.header")
+	if A != nil {
+		s = C.H("top.header.guests", escape(strings.Join(A, ", @")))
+	}
+	var n int
+	for _, i := range I {
+		m := &C.movies[i]
+		v := votesFrom(m, A)
+		if v == 0 || n == maxTop {
+			break
+		}
+		n++
+		s += C.HN("top.item", v, n, escape(m.Title), m.Year, i, v)
+	}
+	if n == 0 {
+		s = C.H("top.none")
+	}
+	msg := tgbotapi.NewMessage(u.Message.Chat.ID, s)
+	msg.ReplyToMessageID = u.Message.MessageID
+	sendHTML(bot, msg)
+}
+
+func saveTonight(C *Chat) {
+	f, err := os.Create(C.prefix + "tonight.json")
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	defer f.Close()
+	b, err := json.Marshal(C.tonight)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	_, err = f.Write(b)
+	if err != nil {
+		log.Printf("Error: %v", err)
+	}
+}
+
+func loadTonight(C *Chat) {
+	f, err := os.Open(C.prefix + "tonight.json")
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	if len(b) < 5 {
+		return
+	}
+	err = json.Unmarshal(b, &C.tonight)
+	if err != nil {
+		log.Printf("Error: %v", err)
+	}
+}
@@ -17,6 +17,16 @@ func RegisterUser(u *tgbotapi.Update) {
 		C.allUsers[usr] = u.Message.From
 		saveUsers(C)
 	}
+	if id := u.Message.From.ID; id != 0 && !C.members[id] {
+		C.members[id] = true
+		saveMembers(C)
+	}
+}
+
+// isMember returns whether the user with Telegram ID id has been seen in C. Unlike usernames,
+// which may be missing, changed or taken over by someone else, IDs tell users apart for good.
+func (C *Chat) isMember(id int) bool {
+	return id != 0 && C.members[id]
 }
 
 func (C *Chat) User(username string) (*tgbotapi.User, bool) {
@@ -88,5 +98,59 @@ func RemoveLeavers(u *tgbotapi.Update) {
 			delete(C.allUsers, uname)
 			saveUsers(C)
 		}
+		if C.members[user.ID] {
+			delete(C.members, user.ID)
+			saveMembers(C)
+		}
+	}
+}
+
+func saveMembers(C *Chat) {
+	f, err := os.Create(C.prefix + "members.json")
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	defer f.Close()
+	b, err := json.Marshal(C.members)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	_, err = f.Write(b)
+	if err != nil {
+		log.Printf("Error: %v", err)
+	}
+}
+
+// loadMembers reads who is in C by their IDs. Chats from before members.json get theirs from
+// the users they had, leaving out those without a username, who can't be told apart.
+func loadMembers(C *Chat) {
+	f, err := os.Open(C.prefix + "members.json")
+	if os.IsNotExist(err) {
+		for usr, u := range C.allUsers {
+			if usr != "" && u != nil && u.ID != 0 {
+				C.members[u.ID] = true
+			}
+		}
+		saveMembers(C)
+		return
+	}
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	if len(b) < 5 {
+		return
+	}
+	err = json.Unmarshal(b, &C.members)
+	if err != nil {
+		log.Printf("Error: %v", err)
 	}
 }